@@ -1,10 +1,14 @@
 package clanglex
 
 // Lexicalize
-func Lexicalize(src string) ([]*Token, error) {
-	l := NewLexer(src)
+// WithRecovery を指定した場合はエラーがあってもトークン列と ErrorList を返す
+func Lexicalize(src string, opts ...Option) ([]*Token, error) {
+	l := NewLexer(src, opts...)
 	tokens, err := l.lexicalize()
 	if err != nil {
+		if _, ok := err.(ErrorList); ok {
+			return tokens, err
+		}
 		return nil, err
 	}
 	return tokens, nil
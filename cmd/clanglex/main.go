@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,17 +11,63 @@ import (
 )
 
 func main() {
+	recovery := flag.Bool("recover", false, "report every lexical error instead of stopping at the first one")
+	flag.Parse()
 
-	input, err := ioutil.ReadAll(os.Stdin)
-	if err != nil {
-		panic(err)
+	opts := []clanglex.Option{}
+	if *recovery {
+		opts = append(opts, clanglex.WithRecovery())
+	}
+
+	if flag.NArg() == 0 {
+		input, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if !lex(string(input), append(opts, clanglex.WithFileName("<stdin>"))...) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	ok := true
+	for _, name := range flag.Args() {
+		input, err := ioutil.ReadFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ok = false
+			continue
+		}
+		if !lex(string(input), append(opts, clanglex.WithFileName(name))...) {
+			ok = false
+		}
+	}
+	if !ok {
+		os.Exit(1)
 	}
+}
 
-	tokens, err := clanglex.Lexicalize(string(input))
+func lex(input string, opts ...clanglex.Option) bool {
+	tokens, err := clanglex.Lexicalize(input, opts...)
 	if err != nil {
-		panic(err)
+		// file:line:col: error: msg
+		var el clanglex.ErrorList
+		var le *clanglex.LexError
+		if errors.As(err, &el) {
+			for _, e := range el {
+				fmt.Fprintf(os.Stderr, "%s: error: %s\n", e.Pos, e.Msg)
+			}
+		} else if errors.As(err, &le) {
+			fmt.Fprintf(os.Stderr, "%s: error: %s\n", le.Pos, le.Msg)
+		} else {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		}
+		if tokens == nil {
+			return false
+		}
 	}
 
 	fmt.Println(tokens)
-
+	return err == nil
 }
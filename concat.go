@@ -0,0 +1,80 @@
+package clanglex
+
+import "strings"
+
+// ConcatStrings は隣り合う文字列リテラル("abc" "def")を1つの Str トークンにまとめる
+// まとめたトークンの Pieces に元のトークンを記録し, Pos と End は最初と最後のトークンの位置になる
+// 間のコメントはまとめたトークンの後ろに移す
+// 接頭辞は1種類まで混ぜられ(L"a" "b" は L), 異なる接頭辞があれば ErrPrefixMismatch のエラーになる
+func ConcatStrings(tokens []*Token) ([]*Token, error) {
+	res := make([]*Token, 0, len(tokens))
+	for i := 0; i < len(tokens); {
+		t := tokens[i]
+		if t.TokenType != Str {
+			res = append(res, t)
+			i++
+			continue
+		}
+
+		// end は最後の文字列リテラルの次
+		pieces := []*Token{t}
+		end := i + 1
+		for j := i + 1; j < len(tokens); j++ {
+			if tokens[j].TokenType == Comment {
+				continue
+			}
+			if tokens[j].TokenType != Str {
+				break
+			}
+			pieces = append(pieces, tokens[j])
+			end = j + 1
+		}
+		if len(pieces) == 1 {
+			res = append(res, t)
+			i++
+			continue
+		}
+
+		ct, err := concat(pieces)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ct)
+		for _, c := range tokens[i+1 : end] {
+			if c.TokenType == Comment {
+				res = append(res, c)
+			}
+		}
+		i = end
+	}
+	return res, nil
+}
+
+// concat は文字列リテラルの並び pieces を1つのトークンにする
+func concat(pieces []*Token) (*Token, error) {
+	prefix := ""
+	lits := make([]string, len(pieces))
+	raws := make([]string, len(pieces))
+	for i, p := range pieces {
+		if p.Prefix != "" && prefix != "" && p.Prefix != prefix {
+			return nil, &LexError{Kind: ErrPrefixMismatch, Pos: p.Pos, Msg: "unsupported non-standard concatenation of string literals"}
+		}
+		if p.Prefix != "" {
+			prefix = p.Prefix
+		}
+		lits[i] = p.Literal
+		raws[i] = p.Raw
+	}
+	first, last := pieces[0], pieces[len(pieces)-1]
+	return &Token{
+		TokenType: Str,
+		Literal:   strings.Join(lits, " "),
+		Pos:       first.Pos,
+		End:       last.End,
+		Origin:    first.Origin,
+		Raw:       strings.Join(raws, " "),
+		Prefix:    prefix,
+		Pieces:    pieces,
+		enc:       first.enc,
+	}, nil
+}
@@ -0,0 +1,312 @@
+package clanglex
+
+import "strings"
+
+// Dialect は C の規格やコンパイラごとのキーワードの集合と字句の拡張
+type Dialect struct {
+	Name     string
+	keywords map[string]TokenType
+	features Feature
+}
+
+// Feature は Dialect が受け付ける字句の拡張
+type Feature int
+
+const (
+	// @ を At トークンにする(組込み向けコンパイラの絶対番地指定)
+	FeatureAtSign Feature = 1 << iota
+	// MSVC の整数の接尾辞(i64, ui64 など)
+	FeatureMSIntSuffix
+	// MSVC の __asm { ... } と __asm mov eax, 1 をまとめて AsmBlock トークンにする
+	FeatureMSAsmBlock
+)
+
+// WithDialect はキーワードとして扱う綴りを規格 d に合わせる
+// 指定しなければ従来どおりの一部のキーワードのみを判別する(int や char は Word になる)
+func WithDialect(d *Dialect) Option {
+	return func(l *Lexer) {
+		l.dialect = d
+	}
+}
+
+var (
+	C89 = newDialect("c89", nil, c89Keywords)
+	C99 = newDialect("c99", C89, c99Keywords)
+	C11 = newDialect("c11", C99, c11Keywords)
+	C17 = newDialect("c17", C11, nil)
+	C23 = newDialect("c23", C17, c23Keywords)
+
+	GNU89 = GNU(C89)
+	GNU99 = GNU(C99)
+	GNU11 = GNU(C11)
+	GNU17 = GNU(C17)
+	GNU23 = GNU(C23)
+)
+
+// GNU は規格 base に GNU C 拡張のキーワードと別名を加えた Dialect を返す
+// __inline__ や __asm__ などの別名は規格のキーワードと同じトークンになる
+func GNU(base *Dialect) *Dialect {
+	return newDialect("gnu"+strings.TrimPrefix(base.Name, "c"), base, gnuKeywords)
+}
+
+// IAR は規格 base に IAR Embedded Workbench のキーワードと @ による絶対番地指定を加えた Dialect を返す
+func IAR(base *Dialect) *Dialect {
+	return NewDialect("iar-"+base.Name, base, iarKeywords, FeatureAtSign)
+}
+
+// CCRX は規格 base に Renesas CC-RX のキーワードを加えた Dialect を返す
+func CCRX(base *Dialect) *Dialect {
+	return newDialect("ccrx-"+base.Name, base, ccrxKeywords)
+}
+
+// RenesasCA は規格 base に Renesas CA78K0R / CA850 のキーワードを加えた Dialect を返す
+func RenesasCA(base *Dialect) *Dialect {
+	return newDialect("ca-"+base.Name, base, renesasCAKeywords)
+}
+
+// GreenHills は規格 base に Green Hills のキーワードを加えた Dialect を返す
+func GreenHills(base *Dialect) *Dialect {
+	return newDialect("ghs-"+base.Name, base, greenHillsKeywords)
+}
+
+// TI は規格 base に TI のコンパイラのキーワードを加えた Dialect を返す
+func TI(base *Dialect) *Dialect {
+	return newDialect("ti-"+base.Name, base, tiKeywords)
+}
+
+// Keil は規格 base に Keil C51 のキーワードを加えた Dialect を返す
+func Keil(base *Dialect) *Dialect {
+	return newDialect("keil-"+base.Name, base, keilKeywords)
+}
+
+// NewDialect は base のキーワードと字句の拡張に kw と features を加えた Dialect を作る
+// base が nil ならキーワードは kw のみになる
+func NewDialect(name string, base *Dialect, kw map[string]TokenType, features Feature) *Dialect {
+	d := &Dialect{Name: name, keywords: map[string]TokenType{}, features: features}
+	if base != nil {
+		for k, v := range base.keywords {
+			d.keywords[k] = v
+		}
+		d.features |= base.features
+	}
+	for k, v := range kw {
+		d.keywords[k] = v
+	}
+	return d
+}
+
+func newDialect(name string, base *Dialect, kw map[string]TokenType) *Dialect {
+	return NewDialect(name, base, kw, 0)
+}
+
+// Has は字句の拡張 f を受け付けるか返す
+func (d *Dialect) Has(f Feature) bool {
+	return d.features&f != 0
+}
+
+// IsKeyword は w が d のキーワードか返す
+func (d *Dialect) IsKeyword(w string) bool {
+	_, ok := d.keywords[w]
+	return ok
+}
+
+var c89Keywords = map[string]TokenType{
+	"auto":     KeyAuto,
+	"break":    KeyBreak,
+	"case":     KeyCase,
+	"char":     KeyChar,
+	"const":    KeyConst,
+	"continue": KeyContinue,
+	"default":  KeyDefault,
+	"do":       KeyDo,
+	"double":   KeyDouble,
+	"else":     KeyElse,
+	"enum":     KeyEnum,
+	"extern":   KeyExtern,
+	"float":    KeyFloat,
+	"for":      KeyFor,
+	"goto":     KeyGoto,
+	"if":       KeyIf,
+	"int":      KeyInt,
+	"long":     KeyLong,
+	"register": KeyRegister,
+	"return":   KeyReturn,
+	"short":    KeyShort,
+	"signed":   KeySigned,
+	"sizeof":   KeySizeof,
+	"static":   KeyStatic,
+	"struct":   KeyStruct,
+	"switch":   KeySwitch,
+	"typedef":  KeyTypedef,
+	"union":    KeyUnion,
+	"unsigned": KeyUnsigned,
+	"void":     KeyVoid,
+	"volatile": KeyVolatile,
+	"while":    KeyWhile,
+}
+
+var c99Keywords = map[string]TokenType{
+	"inline":     KeyInline,
+	"restrict":   KeyRestrict,
+	"_Bool":      KeyBool,
+	"_Complex":   KeyComplex,
+	"_Imaginary": KeyImaginary,
+}
+
+var c11Keywords = map[string]TokenType{
+	"_Alignas":       KeyAlignas,
+	"_Alignof":       KeyAlignof,
+	"_Atomic":        KeyAtomic,
+	"_Generic":       KeyGeneric,
+	"_Noreturn":      KeyNoreturn,
+	"_Static_assert": KeyStaticAssert,
+	"_Thread_local":  KeyThreadLocal,
+}
+
+// C23 では _Bool などに加えて bool などの綴りもキーワードになる
+var c23Keywords = map[string]TokenType{
+	"alignas":       KeyAlignas,
+	"alignof":       KeyAlignof,
+	"bool":          KeyBool,
+	"constexpr":     KeyConstexpr,
+	"false":         KeyFalse,
+	"nullptr":       KeyNullptr,
+	"static_assert": KeyStaticAssert,
+	"thread_local":  KeyThreadLocal,
+	"true":          KeyTrue,
+	"typeof":        KeyTypeof,
+	"typeof_unqual": KeyTypeofUnqual,
+	"_BitInt":       KeyBitInt,
+	"_Decimal32":    KeyDecimal32,
+	"_Decimal64":    KeyDecimal64,
+	"_Decimal128":   KeyDecimal128,
+}
+
+// GNU C 拡張のキーワード
+var gnuKeywords = map[string]TokenType{
+	"__extension__":     KeyExtension,
+	"__inline":          KeyInline,
+	"__inline__":        KeyInline,
+	"__restrict":        KeyRestrict,
+	"__restrict__":      KeyRestrict,
+	"typeof":            KeyTypeof,
+	"__typeof":          KeyTypeof,
+	"__typeof__":        KeyTypeof,
+	"__typeof_unqual__": KeyTypeofUnqual,
+	"asm":               KeyAsm,
+	"__asm":             KeyAsm,
+	"__asm__":           KeyAsm,
+	"__volatile":        KeyVolatile,
+	"__volatile__":      KeyVolatile,
+	"__const":           KeyConst,
+	"__const__":         KeyConst,
+	"__signed":          KeySigned,
+	"__signed__":        KeySigned,
+	"__alignof":         KeyAlignof,
+	"__alignof__":       KeyAlignof,
+	"__complex__":       KeyComplex,
+	"__thread":          KeyThreadLocal,
+	"__attribute":       KeyAttribute,
+	"__attribute__":     KeyAttribute,
+	"__builtin_va_list": KeyBuiltinVaList,
+	"__label__":         KeyLabel,
+	"__auto_type":       KeyAutoType,
+	"__int128":          KeyInt128,
+}
+
+// IAR Embedded Workbench のキーワード
+var iarKeywords = map[string]TokenType{
+	"__interrupt": KeyInterrupt,
+	"__nested":    KeyInterrupt,
+	"__near":      KeyNear,
+	"__far":       KeyFar,
+	"__huge":      KeyHuge,
+	"__no_init":   KeyNoInit,
+	"__ramfunc":   KeyRamfunc,
+	"__root":      KeyRoot,
+	"__intrinsic": KeyIntrinsic,
+	"__monitor":   KeyMonitor,
+	"__task":      KeyTask,
+	"__packed":    KeyPacked,
+	"__weak":      KeyWeak,
+	"__noreturn":  KeyNoreturn,
+	"__asm":       KeyAsm,
+	"asm":         KeyAsm,
+	"__inline":    KeyInline,
+	"_Pragma":     KeyPragma,
+}
+
+// Renesas CC-RX のキーワード
+var ccrxKeywords = map[string]TokenType{
+	"__evenaccess": KeyEvenaccess,
+	"__asm":        KeyAsm,
+	"__inline":     KeyInline,
+	"_Pragma":      KeyPragma,
+}
+
+// Renesas CA78K0R / CA850 のキーワード
+var renesasCAKeywords = map[string]TokenType{
+	"__interrupt":       KeyInterrupt,
+	"__multi_interrupt": KeyInterrupt,
+	"__near":            KeyNear,
+	"__far":             KeyFar,
+	"__saddr":           KeySaddr,
+	"__sreg":            KeySaddr,
+	"__callt":           KeyCallt,
+	"__asm":             KeyAsm,
+	"__inline":          KeyInline,
+	"_Pragma":           KeyPragma,
+}
+
+// Green Hills のキーワード
+var greenHillsKeywords = map[string]TokenType{
+	"__interrupt":   KeyInterrupt,
+	"__packed":      KeyPacked,
+	"__asm":         KeyAsm,
+	"asm":           KeyAsm,
+	"__inline":      KeyInline,
+	"__attribute__": KeyAttribute,
+	"_Pragma":       KeyPragma,
+}
+
+// TI のコンパイラのキーワード
+var tiKeywords = map[string]TokenType{
+	"__interrupt":   KeyInterrupt,
+	"interrupt":     KeyInterrupt,
+	"__cregister":   KeyCregister,
+	"cregister":     KeyCregister,
+	"__near":        KeyNear,
+	"near":          KeyNear,
+	"__far":         KeyFar,
+	"far":           KeyFar,
+	"__asm":         KeyAsm,
+	"asm":           KeyAsm,
+	"__inline":      KeyInline,
+	"__restrict":    KeyRestrict,
+	"__attribute__": KeyAttribute,
+	"_Pragma":       KeyPragma,
+}
+
+// Keil C51 のキーワード
+var keilKeywords = map[string]TokenType{
+	"data":      KeyMemoryType,
+	"idata":     KeyMemoryType,
+	"xdata":     KeyMemoryType,
+	"pdata":     KeyMemoryType,
+	"code":      KeyMemoryType,
+	"bdata":     KeyMemoryType,
+	"small":     KeyMemoryType,
+	"compact":   KeyMemoryType,
+	"large":     KeyMemoryType,
+	"far":       KeyFar,
+	"bit":       KeyBit,
+	"sbit":      KeySbit,
+	"sfr":       KeySfr,
+	"sfr16":     KeySfr16,
+	"interrupt": KeyInterrupt,
+	"using":     KeyUsing,
+	"reentrant": KeyReentrant,
+	"_at_":      KeyAt,
+	"_task_":    KeyTask,
+	"__asm":     KeyAsm,
+}
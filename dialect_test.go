@@ -0,0 +1,311 @@
+package clanglex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDialect(t *testing.T) {
+	src := `int char unsigned register auto inline restrict _Bool _Atomic _Static_assert _Generic _Alignas _Noreturn _Thread_local bool true false nullptr constexpr typeof __attribute__`
+	testTbl := []struct {
+		comment string
+		dialect *Dialect
+		expect  []TokenType
+	}{
+		{
+			"test default",
+			nil,
+			[]TokenType{Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, KeyAttribute},
+		},
+		{
+			"test c89",
+			C89,
+			[]TokenType{KeyInt, KeyChar, KeyUnsigned, KeyRegister, KeyAuto, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word},
+		},
+		{
+			"test c99",
+			C99,
+			[]TokenType{KeyInt, KeyChar, KeyUnsigned, KeyRegister, KeyAuto, KeyInline, KeyRestrict, KeyBool, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word, Word},
+		},
+		{
+			"test c11",
+			C11,
+			[]TokenType{KeyInt, KeyChar, KeyUnsigned, KeyRegister, KeyAuto, KeyInline, KeyRestrict, KeyBool, KeyAtomic, KeyStaticAssert, KeyGeneric, KeyAlignas, KeyNoreturn, KeyThreadLocal, Word, Word, Word, Word, Word, Word, Word},
+		},
+		{
+			"test c17",
+			C17,
+			[]TokenType{KeyInt, KeyChar, KeyUnsigned, KeyRegister, KeyAuto, KeyInline, KeyRestrict, KeyBool, KeyAtomic, KeyStaticAssert, KeyGeneric, KeyAlignas, KeyNoreturn, KeyThreadLocal, Word, Word, Word, Word, Word, Word, Word},
+		},
+		{
+			"test c23",
+			C23,
+			[]TokenType{KeyInt, KeyChar, KeyUnsigned, KeyRegister, KeyAuto, KeyInline, KeyRestrict, KeyBool, KeyAtomic, KeyStaticAssert, KeyGeneric, KeyAlignas, KeyNoreturn, KeyThreadLocal, KeyBool, KeyTrue, KeyFalse, KeyNullptr, KeyConstexpr, KeyTypeof, Word},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		opts := []Option{}
+		if tt.dialect != nil {
+			opts = append(opts, WithDialect(tt.dialect))
+		}
+		got, err := Lexicalize(src, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect)+1 {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect)+1)
+		}
+		for i, e := range tt.expect {
+			if got[i].TokenType != e {
+				t.Errorf("%s: got type=%v, expect type=%v", got[i].Literal, got[i].TokenType, e)
+			}
+		}
+	}
+}
+
+func TestDialectKeywords(t *testing.T) {
+	// C89 の32個のキーワード
+	c89 := []string{"auto", "break", "case", "char", "const", "continue", "default", "do", "double", "else", "enum", "extern", "float", "for", "goto", "if", "int", "long", "register", "return", "short", "signed", "sizeof", "static", "struct", "switch", "typedef", "union", "unsigned", "void", "volatile", "while"}
+	for _, w := range c89 {
+		for _, d := range []*Dialect{C89, C99, C11, C17, C23} {
+			if !d.IsKeyword(w) {
+				t.Errorf("%s is not a keyword in %s", w, d.Name)
+			}
+		}
+	}
+	if len(C89.keywords) != 32 || len(C99.keywords) != 37 || len(C11.keywords) != 44 || len(C17.keywords) != 44 {
+		t.Errorf("got %d %d %d %d keywords", len(C89.keywords), len(C99.keywords), len(C11.keywords), len(C17.keywords))
+	}
+}
+
+func TestGNUDialect(t *testing.T) {
+	testTbl := []struct {
+		src    string
+		expect TokenType
+	}{
+		{"__extension__", KeyExtension},
+		{"__inline__", KeyInline},
+		{"__inline", KeyInline},
+		{"__restrict", KeyRestrict},
+		{"__restrict__", KeyRestrict},
+		{"__typeof__", KeyTypeof},
+		{"typeof", KeyTypeof},
+		{"asm", KeyAsm},
+		{"__asm", KeyAsm},
+		{"__asm__", KeyAsm},
+		{"__volatile__", KeyVolatile},
+		{"__const", KeyConst},
+		{"__signed__", KeySigned},
+		{"__builtin_va_list", KeyBuiltinVaList},
+		{"__label__", KeyLabel},
+		{"__auto_type", KeyAutoType},
+		{"__int128", KeyInt128},
+		{"__attribute__", KeyAttribute},
+		{"__attribute", KeyAttribute},
+		{"int", KeyInt},
+		{"_Static_assert", KeyStaticAssert},
+		{"__foo", Word},
+	}
+	for _, tt := range testTbl {
+		got, err := Lexicalize(tt.src, WithDialect(GNU11))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0].TokenType != tt.expect {
+			t.Errorf("%s: got type=%v, expect type=%v", tt.src, got[0].TokenType, tt.expect)
+		}
+	}
+
+	if GNU99.Name != "gnu99" || !GNU99.IsKeyword("inline") || GNU99.IsKeyword("_Atomic") {
+		t.Errorf("unexpected %s", GNU99.Name)
+	}
+	if !GNU(C89).IsKeyword("__asm__") || C89.IsKeyword("__asm__") {
+		t.Errorf("GNU must not modify the base dialect")
+	}
+}
+
+func TestEmbeddedDialect(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		dialect *Dialect
+		src     string
+		expect  []TokenType
+	}{
+		{
+			"test iar",
+			IAR(C99),
+			"__no_init volatile unsigned char PORT @ 0xFFFF0000; __interrupt __ramfunc void isr(void); __near __far _Pragma",
+			[]TokenType{KeyNoInit, KeyVolatile, KeyUnsigned, KeyChar, Word, At, Integer, Semicolon, KeyInterrupt, KeyRamfunc, KeyVoid, Word, Lparen, KeyVoid, Rparen, Semicolon, KeyNear, KeyFar, KeyPragma},
+		},
+		{
+			"test cc-rx",
+			CCRX(C99),
+			"__evenaccess volatile int reg; __interrupt",
+			[]TokenType{KeyEvenaccess, KeyVolatile, KeyInt, Word, Semicolon, Word},
+		},
+		{
+			"test renesas ca",
+			RenesasCA(C89),
+			"__interrupt void f(void); __saddr __callt __near",
+			[]TokenType{KeyInterrupt, KeyVoid, Word, Lparen, KeyVoid, Rparen, Semicolon, KeySaddr, KeyCallt, KeyNear},
+		},
+		{
+			"test green hills",
+			GreenHills(C99),
+			"__interrupt __packed struct s",
+			[]TokenType{KeyInterrupt, KeyPacked, KeyStruct, Word},
+		},
+		{
+			"test ti",
+			TI(C99),
+			"interrupt void isr(void); cregister far",
+			[]TokenType{KeyInterrupt, KeyVoid, Word, Lparen, KeyVoid, Rparen, Semicolon, KeyCregister, KeyFar},
+		},
+		{
+			"test keil",
+			Keil(C89),
+			"unsigned char xdata buf _at_ 0x8000; sbit P1_0 = 0x90; void isr(void) interrupt 1 using 2",
+			[]TokenType{KeyUnsigned, KeyChar, KeyMemoryType, Word, KeyAt, Integer, Semicolon, KeySbit, Word, Assign, Integer, Semicolon, KeyVoid, Word, Lparen, KeyVoid, Rparen, KeyInterrupt, Integer, KeyUsing, Integer},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src, WithDialect(tt.dialect))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect)+1 {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect)+1)
+		}
+		for i, e := range tt.expect {
+			if got[i].TokenType != e {
+				t.Errorf("%s: got type=%v, expect type=%v", got[i].Literal, got[i].TokenType, e)
+			}
+		}
+	}
+}
+
+func TestAtSign(t *testing.T) {
+	if _, err := Lexicalize("int a @ 0x100;", WithDialect(C99)); !errors.Is(err, ErrUnknownChar) {
+		t.Errorf("got=%v, expect unknown character", err)
+	}
+
+	// 独自のプロファイル
+	d := NewDialect("my-cc", C99, map[string]TokenType{"__io": KeyVolatile}, FeatureAtSign)
+	got, err := Lexicalize("__io int a @ 0x100;", WithDialect(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []TokenType{KeyVolatile, KeyInt, Word, At, Integer, Semicolon, Eof}
+	for i, e := range expect {
+		if got[i].TokenType != e {
+			t.Errorf("%s: got type=%v, expect type=%v", got[i].Literal, got[i].TokenType, e)
+		}
+	}
+}
+
+func TestMSVCDialect(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  []*Token
+	}{
+		{
+			"test keywords",
+			"__declspec(dllexport) unsigned __int64 __cdecl f(__int8 a); __forceinline __inline __pragma",
+			[]*Token{
+				{TokenType: KeyDeclspec, Literal: "__declspec"},
+				{TokenType: Lparen, Literal: "("},
+				{TokenType: Word, Literal: "dllexport"},
+				{TokenType: Rparen, Literal: ")"},
+				{TokenType: KeyUnsigned, Literal: "unsigned"},
+				{TokenType: KeyInt64, Literal: "__int64"},
+				{TokenType: KeyCdecl, Literal: "__cdecl"},
+				{TokenType: Word, Literal: "f"},
+				{TokenType: Lparen, Literal: "("},
+				{TokenType: KeyInt8, Literal: "__int8"},
+				{TokenType: Word, Literal: "a"},
+				{TokenType: Rparen, Literal: ")"},
+				{TokenType: Semicolon, Literal: ";"},
+				{TokenType: KeyForceinline, Literal: "__forceinline"},
+				{TokenType: KeyInline, Literal: "__inline"},
+				{TokenType: KeyPragma, Literal: "__pragma"},
+			},
+		},
+		{
+			"test integer suffix",
+			"100i64 0xFFui64 1I32 2i8 3i16",
+			[]*Token{
+				{TokenType: Integer, Literal: "100i64"},
+				{TokenType: Integer, Literal: "0xFFui64"},
+				{TokenType: Integer, Literal: "1I32"},
+				{TokenType: Integer, Literal: "2i8"},
+				{TokenType: Integer, Literal: "3i16"},
+			},
+		},
+		{
+			"test asm block",
+			"__asm {\n mov eax, 1 ; } in comment\n int 3\n}\nx;",
+			[]*Token{
+				{TokenType: KeyAsm, Literal: "__asm"},
+				{TokenType: AsmBlock, Literal: "\n mov eax, 1 ; } in comment\n int 3\n"},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Semicolon, Literal: ";"},
+			},
+		},
+		{
+			"test asm line",
+			"__asm mov eax, 1 __asm int 3\n_asm nop \nx;",
+			[]*Token{
+				{TokenType: KeyAsm, Literal: "__asm"},
+				{TokenType: AsmBlock, Literal: "mov eax, 1"},
+				{TokenType: KeyAsm, Literal: "__asm"},
+				{TokenType: AsmBlock, Literal: "int 3"},
+				{TokenType: KeyAsm, Literal: "_asm"},
+				{TokenType: AsmBlock, Literal: "nop"},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Semicolon, Literal: ";"},
+			},
+		},
+		{
+			"test asm on next line",
+			"__asm\nx;",
+			[]*Token{
+				{TokenType: KeyAsm, Literal: "__asm"},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Semicolon, Literal: ";"},
+			},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src, WithDialect(MSVC(C99)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect)+1 {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect)+1)
+		}
+		for i, e := range tt.expect {
+			if got[i].TokenType != e.TokenType || got[i].Literal != e.Literal {
+				t.Errorf("got=%v %q, expect=%v %q", got[i].TokenType, got[i].Literal, e.TokenType, e.Literal)
+			}
+		}
+	}
+
+	// MSVC 以外では i64 は接尾辞にならない
+	if _, err := Lexicalize("100i64", WithDialect(C99)); !errors.Is(err, ErrMalformedNumber) {
+		t.Errorf("got=%v, expect malformed number", err)
+	}
+	if _, err := Lexicalize("4i", WithDialect(MSVC(C99))); !errors.Is(err, ErrMalformedNumber) {
+		t.Errorf("got=%v, expect malformed number", err)
+	}
+
+	if _, err := Lexicalize("__asm { mov eax, 1", WithDialect(MSVC(C99))); !errors.Is(err, ErrUnterminatedAsm) {
+		t.Errorf("got=%v, expect unterminated __asm block", err)
+	}
+}
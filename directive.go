@@ -0,0 +1,125 @@
+package clanglex
+
+// WithDirectives はプリプロセッサディレクティブを Comment ではなくトークン列として字句解析する
+// 行頭の # から行末までが Hash, Directive(ディレクティブ名), 通常のトークン, EndDirective の順に出力される
+// 行末の \ による行の継続は取り除く. 識別子と数値の途中にあれば1つのトークンとし, トークンの間にあれば空白とする
+// 文字列, 文字リテラルの中の行の継続はそのまま綴りに残る(値からは取り除く)
+// 演算子の途中の行の継続には対応していない(+\ と改行と = は + と = になる)
+func WithDirectives() Option {
+	return func(l *Lexer) {
+		l.directives = true
+	}
+}
+
+type directiveState struct {
+	// 行頭から空白とコメントしか読んでいない
+	lineStart bool
+	// ディレクティブの途中
+	in bool
+	// 次のワードはディレクティブ名
+	expectName bool
+	// 次の < はヘッダ名の開始
+	expectHeader bool
+	// ディレクティブの行の内容(ラインマーカの解釈に使う)
+	text string
+}
+
+// lineSplice は i の位置が \ と改行なら読み飛ばすバイト数を返す
+func (l *Lexer) lineSplice(i int) int {
+	if i+1 < len(l.input) && l.input[i] == '\\' {
+		if l.input[i+1] == '\n' {
+			return 2
+		}
+		if i+2 < len(l.input) && l.input[i+1] == '\r' && l.input[i+2] == '\n' {
+			return 3
+		}
+	}
+	return 0
+}
+
+// readHash は # と ## を読む
+// 行頭の # はディレクティブの開始とする
+func (l *Lexer) readHash() *Token {
+	l.pos++
+	if l.pos < len(l.input) && l.input[l.pos] == '#' {
+		l.pos++
+		return &Token{TokenType: HashHash, Literal: "##"}
+	}
+	if l.dir.lineStart && !l.dir.in {
+		l.dir.in = true
+		l.dir.expectName = true
+		l.dir.text = l.restOfDirective()
+	}
+	return &Token{TokenType: Hash, Literal: "#"}
+}
+
+// restOfDirective は現在位置からディレクティブの終わりまでの文字列を行の継続を除いて返す
+func (l *Lexer) restOfDirective() string {
+	res := []byte{}
+	i := l.pos
+	for ; i < len(l.input); i++ {
+		if n := l.enc.charLen(l.input, i); n > 1 {
+			res = append(res, l.input[i:i+n]...)
+			i += n - 1
+			continue
+		}
+		if n := l.lineSplice(i); n > 0 {
+			i += n - 1
+			continue
+		}
+		if l.input[i] == '\n' {
+			break
+		}
+		res = append(res, l.input[i])
+	}
+	if i >= len(l.input) {
+		// 行末まで読めていない
+		l.hitEnd = true
+	}
+	return string(res)
+}
+
+func (l *Lexer) readDirectiveName() *Token {
+	w := l.readIdent()
+	switch w {
+	case "include", "include_next", "import":
+		l.dir.expectHeader = true
+	}
+	return &Token{TokenType: Directive, Literal: w}
+}
+
+// readHeaderName は #include の <...> を読む
+// 行末までに > がなければ nil を返す
+func (l *Lexer) readHeaderName() *Token {
+	for next := l.pos + 1; next < len(l.input); next++ {
+		c := l.input[next]
+		if c == '\n' {
+			return nil
+		}
+		if c == '>' {
+			w := l.input[l.pos : next+1]
+			l.pos = next + 1
+			return &Token{TokenType: HeaderName, Literal: w}
+		}
+	}
+	l.hitEnd = true
+	return nil
+}
+
+// endDirective はディレクティブの終わりを表すトークンを返す
+// ディレクティブがラインマーカなら以降のトークンの元ファイルでの位置を更新する
+func (l *Lexer) endDirective() *Token {
+	start := l.pos
+	if l.pos < len(l.input) {
+		l.pos++
+	}
+	tk := &Token{TokenType: EndDirective, Literal: "", Raw: l.input[start:l.pos]}
+	tk.Pos = l.position(start)
+	tk.End = l.position(l.pos)
+	tk.Origin = l.origin(tk.Pos.Line)
+
+	// 行の継続があればディレクティブの終わりの行の次がマーカの示す行になる
+	l.applyLinemarker(l.dir.text, tk.Pos.Line)
+	l.dir = directiveState{lineStart: true}
+	return tk
+}
@@ -0,0 +1,185 @@
+package clanglex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDirectives(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  []*Token
+	}{
+		{
+			"test include",
+			"#include <stdio.h>\n#  include \"hoge.h\"\n",
+			[]*Token{
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Directive, Literal: "include"},
+				{TokenType: HeaderName, Literal: "<stdio.h>"},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Directive, Literal: "include"},
+				{TokenType: Str, Literal: `"hoge.h"`},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test splice in token",
+			"#def\\\nine AB\\\nCD 12\\\r\n34 0x1p\\\n+3 \\\nx\nab\\\ncd",
+			[]*Token{
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Directive, Literal: "define"},
+				{TokenType: Word, Literal: "ABCD"},
+				{TokenType: Integer, Literal: "1234"},
+				{TokenType: Float, Literal: "0x1p+3"},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Word, Literal: "abcd"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test define",
+			"#define MAX(a, b) \\\n  ((a) > (b) ? (a) : (b))\nint x;",
+			[]*Token{
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Directive, Literal: "define"},
+				{TokenType: Word, Literal: "MAX"},
+				{TokenType: Lparen, Literal: "("},
+				{TokenType: Word, Literal: "a"},
+				{TokenType: Comma, Literal: ","},
+				{TokenType: Word, Literal: "b"},
+				{TokenType: Rparen, Literal: ")"},
+				{TokenType: Lparen, Literal: "("},
+				{TokenType: Lparen, Literal: "("},
+				{TokenType: Word, Literal: "a"},
+				{TokenType: Rparen, Literal: ")"},
+				{TokenType: Gt, Literal: ">"},
+				{TokenType: Lparen, Literal: "("},
+				{TokenType: Word, Literal: "b"},
+				{TokenType: Rparen, Literal: ")"},
+				{TokenType: Question, Literal: "?"},
+				{TokenType: Lparen, Literal: "("},
+				{TokenType: Word, Literal: "a"},
+				{TokenType: Rparen, Literal: ")"},
+				{TokenType: Colon, Literal: ":"},
+				{TokenType: Lparen, Literal: "("},
+				{TokenType: Word, Literal: "b"},
+				{TokenType: Rparen, Literal: ")"},
+				{TokenType: Rparen, Literal: ")"},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Word, Literal: "int"},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Semicolon, Literal: ";"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test stringize and paste",
+			"#define CAT(a, b) a ## b # a\n#if 1 /* c */\n#else\n#endif",
+			[]*Token{
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Directive, Literal: "define"},
+				{TokenType: Word, Literal: "CAT"},
+				{TokenType: Lparen, Literal: "("},
+				{TokenType: Word, Literal: "a"},
+				{TokenType: Comma, Literal: ","},
+				{TokenType: Word, Literal: "b"},
+				{TokenType: Rparen, Literal: ")"},
+				{TokenType: Word, Literal: "a"},
+				{TokenType: HashHash, Literal: "##"},
+				{TokenType: Word, Literal: "b"},
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Word, Literal: "a"},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Directive, Literal: "if"},
+				{TokenType: Integer, Literal: "1"},
+				{TokenType: Comment, Literal: " c "},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Directive, Literal: "else"},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Directive, Literal: "endif"},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test pragma and linemarker",
+			"  # pragma section\n# 1 \"a.c\" 1\nx # y",
+			[]*Token{
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Directive, Literal: "pragma"},
+				{TokenType: Word, Literal: "section"},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Integer, Literal: "1"},
+				{TokenType: Str, Literal: `"a.c"`},
+				{TokenType: Integer, Literal: "1"},
+				{TokenType: EndDirective, Literal: ""},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Hash, Literal: "#"},
+				{TokenType: Word, Literal: "y"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src, WithDirectives())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect) {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect))
+		}
+		for i, v := range got {
+			e := tt.expect[i]
+			if v.TokenType != e.TokenType {
+				t.Errorf("got type=%v, expect type=%v", v, e)
+			}
+			if v.Literal != e.Literal {
+				t.Errorf("got literal=%v, expect literal=%v", v.Literal, e.Literal)
+			}
+		}
+	}
+}
+
+func TestDirectiveLinemarker(t *testing.T) {
+	got, err := Lexicalize("# 10 \"a.c\"\nx\n#line 20 \"b.c\"\ny", WithDirectives())
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := got[len(got)-8]
+	y := got[len(got)-2]
+	if x.Literal != "x" || x.Origin.File != "a.c" || x.Origin.Line != 10 {
+		t.Errorf("got %v %+v", x, x.Origin)
+	}
+	if y.Literal != "y" || y.Origin.File != "b.c" || y.Origin.Line != 20 {
+		t.Errorf("got %v %+v", y, y.Origin)
+	}
+}
+
+func TestManyDirectives(t *testing.T) {
+	// ディレクティブごとに先頭から位置を数え直すと終わらない
+	const n = 50000
+	src := strings.Repeat("#define X 1\n", n) + "x"
+	got, err := Lexicalize(src, WithDirectives())
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := got[len(got)-2]
+	if x.Literal != "x" || x.Pos.Line != n+1 || x.Pos.Column != 1 {
+		t.Errorf("got %v %+v", x, x.Pos)
+	}
+	hash := got[len(got)-7]
+	if hash.TokenType != Hash || hash.Pos.Line != n || hash.Pos.Offset != (n-1)*12 {
+		t.Errorf("got %v %+v", hash, hash.Pos)
+	}
+}
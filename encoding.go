@@ -0,0 +1,79 @@
+package clanglex
+
+import "fmt"
+
+// Encoding はソースの文字コード
+type Encoding int
+
+const (
+	UTF8 Encoding = iota
+	ShiftJIS
+	CP932
+	EUCJP
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case UTF8:
+		return "UTF-8"
+	case ShiftJIS:
+		return "Shift_JIS"
+	case CP932:
+		return "CP932"
+	case EUCJP:
+		return "EUC-JP"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// WithEncoding はソースの文字コードを指定する
+// Shift_JIS と CP932 では2バイト目が \ (0x5C) の文字(ソ, 表など)をエスケープや行の継続と読まない
+// 変換はしないので, トークンの綴りと位置は元のバイト列のままになる
+func WithEncoding(e Encoding) Option {
+	return func(l *Lexer) {
+		l.enc = e
+	}
+}
+
+// charLen は s[i] から始まる文字のバイト数を返す
+// マルチバイト文字でなければ 1 を返す
+func (e Encoding) charLen(s string, i int) int {
+	c := s[i]
+	if c < 0x80 {
+		return 1
+	}
+	switch e {
+	case ShiftJIS, CP932:
+		lead := 0x81 <= c && c <= 0x9F || 0xE0 <= c && c <= 0xEF
+		if e == CP932 {
+			// ユーザ定義文字と IBM 拡張文字
+			lead = lead || 0xF0 <= c && c <= 0xFC
+		}
+		if lead && i+1 < len(s) {
+			if t := s[i+1]; 0x40 <= t && t <= 0x7E || 0x80 <= t && t <= 0xFC {
+				return 2
+			}
+		}
+	case EUCJP:
+		n := 0
+		switch {
+		case c == 0x8E:
+			// 半角カナ
+			n = 2
+		case c == 0x8F:
+			// 補助漢字
+			n = 3
+		case 0xA1 <= c && c <= 0xFE:
+			n = 2
+		}
+		if n > 0 && i+n <= len(s) {
+			for j := i + 1; j < i+n; j++ {
+				if s[j] < 0xA1 || s[j] == 0xFF {
+					return 1
+				}
+			}
+			return n
+		}
+	}
+	return 1
+}
@@ -0,0 +1,186 @@
+package clanglex
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Shift_JIS の "ソ" と "表"(2バイト目が 0x5C)
+const (
+	sjisSo  = "\x83\x5C"
+	sjisHyo = "\x95\x5C"
+)
+
+func TestEncoding(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		enc     Encoding
+		expect  []Token
+	}{
+		{
+			"test sjis string",
+			`"` + sjisSo + `" x`,
+			ShiftJIS,
+			[]Token{
+				{TokenType: Str, Literal: `"` + sjisSo + `"`},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test sjis char",
+			`'` + sjisHyo + `' x`,
+			CP932,
+			[]Token{
+				{TokenType: Letter, Literal: `'` + sjisHyo + `'`},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test sjis line comment",
+			"// " + sjisHyo + "\nx",
+			ShiftJIS,
+			[]Token{
+				{TokenType: Comment, Literal: " " + sjisHyo},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test sjis block comment",
+			"/* " + sjisSo + " */x",
+			ShiftJIS,
+			[]Token{
+				{TokenType: Comment, Literal: " " + sjisSo + " "},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test sjis directive",
+			"#define S \"" + sjisSo + "\"\nx",
+			ShiftJIS,
+			[]Token{
+				{TokenType: Comment, Literal: "define S \"" + sjisSo + "\""},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test escape after sjis",
+			`"` + sjisSo + `\"" x`,
+			ShiftJIS,
+			[]Token{
+				{TokenType: Str, Literal: `"` + sjisSo + `\""`},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test euc-jp string",
+			"\"\xA5\xBD\\\\\" x",
+			EUCJP,
+			[]Token{
+				{TokenType: Str, Literal: "\"\xA5\xBD\\\\\""},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src, WithEncoding(tt.enc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect) {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect))
+		}
+		for i, v := range got {
+			if v.TokenType != tt.expect[i].TokenType || v.Literal != tt.expect[i].Literal {
+				t.Errorf("got=%v %q, expect=%v %q", v.TokenType, v.Literal, tt.expect[i].TokenType, tt.expect[i].Literal)
+			}
+		}
+	}
+}
+
+func TestEncodingUTF8(t *testing.T) {
+	// UTF-8 では 0x5C はエスケープになるので文字列が閉じない
+	_, err := Lexicalize(`"` + sjisSo + `" x`)
+	if !errors.Is(err, ErrUnterminatedString) {
+		t.Errorf("got err=%v, expect %v", err, ErrUnterminatedString)
+	}
+}
+
+func TestEncodingPosition(t *testing.T) {
+	src := "/*" + sjisSo + "*/ x\n\"" + sjisHyo + "\" y"
+	got, err := Lexicalize(src, WithEncoding(ShiftJIS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Position{
+		{"", 0, 1, 1, 1},
+		{"", 7, 1, 8, 7},
+		{"", 9, 2, 1, 1},
+		{"", 14, 2, 6, 5},
+		{"", 15, 2, 7, 6},
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("got len=%v, expect len=%v", len(got), len(expect))
+	}
+	for i, v := range got {
+		if v.Pos != expect[i] {
+			t.Errorf("%q: got pos=%v, expect pos=%v", v.Literal, v.Pos, expect[i])
+		}
+	}
+}
+
+func TestEncodingReader(t *testing.T) {
+	src := strings.Repeat(`"`+sjisSo+sjisHyo+`" `, 100)
+	l := NewReaderLexer(strings.NewReader(src), WithEncoding(ShiftJIS))
+	n := 0
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.TokenType == Eof {
+			break
+		}
+		if tok.Literal != `"`+sjisSo+sjisHyo+`"` {
+			t.Fatalf("got literal=%q", tok.Literal)
+		}
+		n++
+	}
+	if n != 100 {
+		t.Errorf("got %v tokens, expect 100", n)
+	}
+}
+
+func TestEncodingValue(t *testing.T) {
+	ts, err := Lexicalize(`"`+sjisSo+`" '`+sjisHyo+`' L"`+sjisSo+`"`, WithEncoding(ShiftJIS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ts[0].StringValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != sjisSo {
+		t.Errorf("got=%q, expect=%q", s, sjisSo)
+	}
+	v, err := ts[1].CharValue(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 0x955C {
+		t.Errorf("got=%#x, expect=%#x", v, 0x955C)
+	}
+	if _, err := ts[2].CodeUnits(nil); !errors.Is(err, ErrEncoding) {
+		t.Errorf("got err=%v, expect %v", err, ErrEncoding)
+	}
+}
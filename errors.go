@@ -0,0 +1,190 @@
+package clanglex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrorKind は字句解析エラーの種類
+// errors.Is で LexError の種類を判別できる
+type ErrorKind int
+
+const (
+	ErrUnknownChar ErrorKind = iota + 1
+	ErrUnterminatedString
+	ErrUnterminatedComment
+	ErrBadEscape
+	ErrMalformedNumber
+	ErrUnterminatedChar
+	ErrUnterminatedAsm
+	ErrOutOfRange
+	ErrEmptyChar
+	ErrPrefixMismatch
+	ErrEncoding
+	ErrBinary
+)
+
+func (k ErrorKind) Error() string {
+	switch k {
+	case ErrUnknownChar:
+		return "unknown character"
+	case ErrUnterminatedString:
+		return "unterminated string"
+	case ErrUnterminatedComment:
+		return "unterminated comment"
+	case ErrBadEscape:
+		return "bad escape sequence"
+	case ErrMalformedNumber:
+		return "malformed number"
+	case ErrUnterminatedChar:
+		return "unterminated character constant"
+	case ErrUnterminatedAsm:
+		return "unterminated __asm block"
+	case ErrOutOfRange:
+		return "number out of range"
+	case ErrEmptyChar:
+		return "empty character constant"
+	case ErrPrefixMismatch:
+		return "mismatched string literal prefixes"
+	case ErrEncoding:
+		return "unconvertible character encoding"
+	case ErrBinary:
+		return "binary input"
+	}
+	return fmt.Sprintf("lexical error(%d)", int(k))
+}
+
+// LexError は字句解析エラー
+type LexError struct {
+	Kind ErrorKind
+	Pos  Position
+	Msg  string
+}
+
+func (e *LexError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+func (e *LexError) Unwrap() error {
+	return e.Kind
+}
+
+// errorf はバイトオフセット off の位置の LexError を返す
+func (l *Lexer) errorf(kind ErrorKind, off int, format string, a ...interface{}) *LexError {
+	return &LexError{Kind: kind, Pos: l.position(off), Msg: fmt.Sprintf(format, a...)}
+}
+
+// ErrorList は複数の字句解析エラー
+type ErrorList []*LexError
+
+func (el ErrorList) Error() string {
+	switch len(el) {
+	case 0:
+		return "no errors"
+	case 1:
+		return el[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", el[0], len(el)-1)
+}
+
+// Is は el のいずれかのエラーが target に当たるか返す
+// Go 1.20 より前の errors.Is は Unwrap() []error をたどらないので自分で調べる
+func (el ErrorList) Is(target error) bool {
+	for _, e := range el {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As は el の最初の target に当てはまるエラーを target に設定する
+func (el ErrorList) As(target interface{}) bool {
+	for _, e := range el {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (el ErrorList) Unwrap() []error {
+	res := make([]error, len(el))
+	for i, e := range el {
+		res[i] = e
+	}
+	return res
+}
+
+// WithRecovery はエラーで字句解析を止めず, 字句解析できなかった部分を Illegal トークンにして続ける
+// エラーはすべて記録され, Lexicalize は Illegal を含むトークン列と ErrorList を返す
+func WithRecovery() Option {
+	return func(l *Lexer) {
+		l.recovery = true
+	}
+}
+
+// Errors はエラー回復モードで記録したエラーを返す
+func (l *Lexer) Errors() ErrorList {
+	return l.errs
+}
+
+// recoverError はエラー回復モードならエラーを記録し, 字句解析できなかった範囲を Illegal トークンにする
+// エラー回復モードでなければ err をそのまま返す
+func (l *Lexer) recoverError(err error) (*Token, error) {
+	var le *LexError
+	if !l.recovery || !errors.As(err, &le) {
+		return nil, err
+	}
+	l.errs = append(l.errs, le)
+
+	start := le.Pos.Offset - l.base
+	end := start + 1
+	switch le.Kind {
+	case ErrUnknownChar:
+		// 字句解析できない文字の並び
+		for end < len(l.input) && !isTokenStart(l.input[end]) {
+			end++
+		}
+	case ErrMalformedNumber:
+		// 数値に続く英数字と . まで
+		for end < len(l.input) && (isIdentChar(l.input[end]) || l.input[end] == '.') {
+			end++
+		}
+	case ErrUnterminatedComment, ErrUnterminatedAsm:
+		end = len(l.input)
+	default:
+		// 行末まで
+		for end < len(l.input) && l.input[end] != '\n' {
+			end++
+		}
+	}
+	l.pos = start
+	return l.newIllegal(end), nil
+}
+
+// charName はエラーメッセージに使う l.input[i] の文字の表記を返す
+// 表示できない文字とマルチバイト文字の途中のバイトは '\xNN' とする
+func (l *Lexer) charName(i int) string {
+	c := l.input[i]
+	if ' ' < c && c < 0x7F {
+		return fmt.Sprintf("'%c'", c)
+	}
+	if l.enc == UTF8 && c >= utf8.RuneSelf {
+		if r, n := utf8.DecodeRuneInString(l.input[i:]); n > 1 && unicode.IsPrint(r) {
+			return fmt.Sprintf("'%c'", r)
+		}
+	}
+	return fmt.Sprintf("'\\x%02X'", c)
+}
+
+// isTokenStart は c が空白かトークンの先頭になる文字か返す
+func isTokenStart(c byte) bool {
+	if isLetter(c) || isDec(c) {
+		return true
+	}
+	return strings.IndexByte(" \t\r\n=+-!*/<>;(),{}[]&~^|%:?.\\'\"#", c) >= 0
+}
@@ -0,0 +1,227 @@
+package clanglex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTruncated(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  []string
+	}{
+		{"test zero", "0", []string{"0", "eof"}},
+		{"test zero 2", "a = 0", []string{"a", "=", "0", "eof"}},
+		{"test line comment", "//", []string{"", "eof"}},
+		{"test hash", "#", []string{"", "eof"}},
+		{"test empty comment", "/**/", []string{"", "eof"}},
+	}
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect) {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect))
+		}
+		for i, v := range got {
+			if v.Literal != tt.expect[i] {
+				t.Errorf("got literal=%v, expect literal=%v", v.Literal, tt.expect[i])
+			}
+		}
+	}
+}
+
+func TestLexError(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		kind    ErrorKind
+		pos     Position
+		msg     string
+	}{
+		{
+			"test unknown character",
+			"int a;\n  a = $b;",
+			ErrUnknownChar,
+			Position{"hoge.c", 13, 2, 7, 7},
+			"hoge.c:2:7: unknown character '$'",
+		},
+		{
+			"test unknown byte",
+			"a = \x95\x5C;",
+			ErrUnknownChar,
+			Position{"hoge.c", 4, 1, 5, 5},
+			`hoge.c:1:5: unknown character '\x95'`,
+		},
+		{
+			"test unknown control",
+			"a = b + c + \x01;",
+			ErrUnknownChar,
+			Position{"hoge.c", 12, 1, 13, 13},
+			`hoge.c:1:13: unknown character '\x01'`,
+		},
+		{
+			"test unknown rune",
+			"a = ∑;",
+			ErrUnknownChar,
+			Position{"hoge.c", 4, 1, 5, 5},
+			"hoge.c:1:5: unknown character '∑'",
+		},
+		{
+			"test unterminated string",
+			`a = "abc`,
+			ErrUnterminatedString,
+			Position{"hoge.c", 4, 1, 5, 5},
+			`hoge.c:1:5: missing terminating '"' character`,
+		},
+		{
+			"test unterminated string 2",
+			"\"abc\\",
+			ErrUnterminatedString,
+			Position{"hoge.c", 0, 1, 1, 1},
+			`hoge.c:1:1: missing terminating '"' character`,
+		},
+		{
+			"test string with newline",
+			"\"abc\n\"",
+			ErrUnterminatedString,
+			Position{"hoge.c", 0, 1, 1, 1},
+			`hoge.c:1:1: missing terminating '"' character`,
+		},
+		{
+			"test unterminated comment",
+			"x /* x",
+			ErrUnterminatedComment,
+			Position{"hoge.c", 2, 1, 3, 3},
+			"hoge.c:1:3: unterminated comment",
+		},
+		{
+			"test unterminated comment 2",
+			"/* x *",
+			ErrUnterminatedComment,
+			Position{"hoge.c", 0, 1, 1, 1},
+			"hoge.c:1:1: unterminated comment",
+		},
+		{
+			"test unterminated char",
+			"'",
+			ErrUnterminatedChar,
+			Position{"hoge.c", 0, 1, 1, 1},
+			"hoge.c:1:1: missing terminating ' character",
+		},
+		{
+			"test unterminated char 2",
+			"c = '\\",
+			ErrUnterminatedChar,
+			Position{"hoge.c", 4, 1, 5, 5},
+			"hoge.c:1:5: missing terminating ' character",
+		},
+		{
+			"test unterminated char 3",
+			"'a\n'",
+			ErrUnterminatedChar,
+			Position{"hoge.c", 0, 1, 1, 1},
+			"hoge.c:1:1: missing terminating ' character",
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		_, err := Lexicalize(tt.src, WithFileName("hoge.c"))
+		if err == nil {
+			t.Fatal("expect error")
+		}
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("got=%v, expect kind=%v", err, tt.kind)
+		}
+		var le *LexError
+		if !errors.As(err, &le) {
+			t.Fatalf("got=%T, expect *LexError", err)
+		}
+		if le.Pos != tt.pos {
+			t.Errorf("got pos=%+v, expect pos=%+v", le.Pos, tt.pos)
+		}
+		if err.Error() != tt.msg {
+			t.Errorf("got msg=%q, expect msg=%q", err.Error(), tt.msg)
+		}
+	}
+}
+
+func TestNoPanic(t *testing.T) {
+	src := `# 1 "hoge.c"
+int a = 0x10, b = 0, c = 012UL; /* comment */
+char *s = "\"str\\"; // line
+char c = '\''; char d = '\0';
+`
+	// 途中で切れたソースでもパニックしないこと
+	for i := 0; i <= len(src); i++ {
+		Lexicalize(src[:i])
+		Lexicalize(src[:i], WithDirectives())
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	src := "int $$ a;\nchar *s = \"abc;\nx @ y;\n/* abc"
+	got, err := Lexicalize(src, WithRecovery(), WithFileName("hoge.c"))
+	expect := []struct {
+		tokenType TokenType
+		literal   string
+		pos       Position
+	}{
+		{Word, "int", Position{"hoge.c", 0, 1, 1, 1}},
+		{Illegal, "$$", Position{"hoge.c", 4, 1, 5, 5}},
+		{Word, "a", Position{"hoge.c", 7, 1, 8, 8}},
+		{Semicolon, ";", Position{"hoge.c", 8, 1, 9, 9}},
+		{Word, "char", Position{"hoge.c", 10, 2, 1, 1}},
+		{Asterisk, "*", Position{"hoge.c", 15, 2, 6, 6}},
+		{Word, "s", Position{"hoge.c", 16, 2, 7, 7}},
+		{Assign, "=", Position{"hoge.c", 18, 2, 9, 9}},
+		{Illegal, `"abc;`, Position{"hoge.c", 20, 2, 11, 11}},
+		{Word, "x", Position{"hoge.c", 26, 3, 1, 1}},
+		{Illegal, "@", Position{"hoge.c", 28, 3, 3, 3}},
+		{Word, "y", Position{"hoge.c", 30, 3, 5, 5}},
+		{Semicolon, ";", Position{"hoge.c", 31, 3, 6, 6}},
+		{Illegal, "/* abc", Position{"hoge.c", 33, 4, 1, 1}},
+		{Eof, "eof", Position{"hoge.c", 39, 4, 7, 7}},
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("got len=%v, expect len=%v", len(got), len(expect))
+	}
+	for i, v := range got {
+		e := expect[i]
+		if v.TokenType != e.tokenType || v.Literal != e.literal || v.Pos != e.pos {
+			t.Errorf("got=%v %+v, expect=%v %+v", v, v.Pos, e.literal, e.pos)
+		}
+	}
+
+	var el ErrorList
+	if !errors.As(err, &el) {
+		t.Fatalf("got=%T, expect ErrorList", err)
+	}
+	kinds := []ErrorKind{ErrUnknownChar, ErrUnterminatedString, ErrUnknownChar, ErrUnterminatedComment}
+	if len(el) != len(kinds) {
+		t.Fatalf("got %d errors, expect %d", len(el), len(kinds))
+	}
+	for i, e := range el {
+		if e.Kind != kinds[i] {
+			t.Errorf("got kind=%v, expect kind=%v", e.Kind, kinds[i])
+		}
+	}
+	if !errors.Is(err, ErrUnterminatedComment) {
+		t.Errorf("errors.Is(%v, ErrUnterminatedComment) = false", err)
+	}
+	// Unwrap() []error をたどらない Go でも Is, As で判別できる
+	if !el.Is(ErrUnterminatedComment) || el.Is(ErrBadEscape) {
+		t.Errorf("got Is=%v %v", el.Is(ErrUnterminatedComment), el.Is(ErrBadEscape))
+	}
+	var le *LexError
+	if !el.As(&le) || le != el[0] {
+		t.Errorf("got As=%v", le)
+	}
+	if err.Error() != "hoge.c:1:5: unknown character '$' (and 3 more errors)" {
+		t.Errorf("got msg=%q", err.Error())
+	}
+}
@@ -0,0 +1,42 @@
+package clanglex
+
+import "strings"
+
+// WithUserFilesOnly はユーザファイル由来のトークンのみを残す
+// ラインマーカにシステムヘッダのフラグ(3)が付いているファイル,
+// またはファイル名が systemPrefixes のいずれかで始まるファイルのトークンを捨てる
+func WithUserFilesOnly(systemPrefixes ...string) Option {
+	return func(l *Lexer) {
+		l.userOnly = true
+		l.systemPrefixes = systemPrefixes
+	}
+}
+
+// UserTokens は tokens からユーザファイル由来のトークンのみを取り出す
+// 判定は WithUserFilesOnly と同じ
+func UserTokens(tokens []*Token, systemPrefixes ...string) []*Token {
+	ts := []*Token{}
+	for _, t := range tokens {
+		if !t.IsSystem(systemPrefixes...) {
+			ts = append(ts, t)
+		}
+	}
+	return ts
+}
+
+// IsSystem はシステムヘッダ由来のトークンか返す
+// Eof は常に false
+func (t *Token) IsSystem(systemPrefixes ...string) bool {
+	if t.TokenType == Eof {
+		return false
+	}
+	if t.Origin.Has(FlagSystemHeader) {
+		return true
+	}
+	for _, p := range systemPrefixes {
+		if strings.HasPrefix(t.Origin.File, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package clanglex
+
+import (
+	"testing"
+)
+
+func TestUserFilesOnly(t *testing.T) {
+	src := `# 1 "main.c"
+# 1 "/usr/include/stdio.h" 1 3 4
+extern int printf;
+# 2 "main.c" 2
+# 1 "/opt/cross/include/stdint.h" 1
+typedef int int32_t;
+# 3 "main.c" 2
+int32_t a;
+`
+	testTbl := []struct {
+		comment  string
+		prefixes []string
+		expect   []string
+	}{
+		{
+			"test system flag",
+			nil,
+			[]string{` 1 "main.c"`, ` 2 "main.c" 2`, ` 1 "/opt/cross/include/stdint.h" 1`, "typedef", "int", "int32_t", ";", ` 3 "main.c" 2`, "int32_t", "a", ";", "eof"},
+		},
+		{
+			"test system prefix",
+			[]string{"/opt/cross/"},
+			[]string{` 1 "main.c"`, ` 2 "main.c" 2`, ` 3 "main.c" 2`, "int32_t", "a", ";", "eof"},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(src, WithUserFilesOnly(tt.prefixes...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		all, err := Lexicalize(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filtered := UserTokens(all, tt.prefixes...)
+		if len(got) != len(tt.expect) || len(filtered) != len(tt.expect) {
+			t.Fatalf("got len=%v, filtered len=%v, expect len=%v", len(got), len(filtered), len(tt.expect))
+		}
+		for i, v := range got {
+			if v.Literal != tt.expect[i] {
+				t.Errorf("got literal=%v, expect literal=%v", v.Literal, tt.expect[i])
+			}
+			if filtered[i].Literal != tt.expect[i] {
+				t.Errorf("filtered literal=%v, expect literal=%v", filtered[i].Literal, tt.expect[i])
+			}
+		}
+	}
+}
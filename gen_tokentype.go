@@ -0,0 +1,97 @@
+//go:build ignore
+// +build ignore
+
+// gen_tokentype は lexer.go の TokenType の定数から tokentype_string.go を生成する
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"strings"
+)
+
+// 名前から分類を決められない種類
+var categories = map[string]string{
+	"Word":    "CategoryIdentifier",
+	"Integer": "CategoryLiteral",
+	"Float":   "CategoryLiteral",
+	"Str":     "CategoryLiteral",
+	"Letter":  "CategoryLiteral",
+	"Comment": "CategoryTrivia",
+
+	"Eof":          "CategoryOther",
+	"Directive":    "CategoryOther",
+	"HeaderName":   "CategoryOther",
+	"EndDirective": "CategoryOther",
+	"AsmBlock":     "CategoryOther",
+	"Illegal":      "CategoryOther",
+}
+
+func category(name string) string {
+	if c, ok := categories[name]; ok {
+		return c
+	}
+	if strings.HasPrefix(name, "Key") {
+		return "CategoryKeyword"
+	}
+	return "CategoryPunctuator"
+}
+
+func main() {
+	f, err := parser.ParseFile(token.NewFileSet(), "lexer.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	names := []string{}
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST || len(gd.Specs) == 0 {
+			continue
+		}
+		if id, ok := gd.Specs[0].(*ast.ValueSpec).Type.(*ast.Ident); !ok || id.Name != "TokenType" {
+			continue
+		}
+		for _, s := range gd.Specs {
+			for _, n := range s.(*ast.ValueSpec).Names {
+				if n.IsExported() {
+					names = append(names, n.Name)
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		log.Fatal("no TokenType constants in lexer.go")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "// Code generated by gen_tokentype.go; DO NOT EDIT.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "package clanglex")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "var tokenTypeNames = [numTokenTypes]string{")
+	for _, n := range names {
+		fmt.Fprintf(&b, "%s: %q,\n", n, n)
+	}
+	fmt.Fprintln(&b, "}")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "var tokenTypeCategories = [numTokenTypes]Category{")
+	for _, n := range names {
+		fmt.Fprintf(&b, "%s: %s,\n", n, category(n))
+	}
+	fmt.Fprintln(&b, "}")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("tokentype_string.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,130 @@
+package clanglex
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// identChar は l.input[i] から始まる識別子の1文字を読み, 文字とバイト数を返す
+// 識別子の文字でなければ n は 0 になる
+// first が true なら識別子の先頭の文字として調べる
+// 英数字と _ のほかに, C23 の XID_Start, XID_Continue の文字を UTF-8 か \u, \U で書ける
+func (l *Lexer) identChar(i int, first bool) (r rune, n int) {
+	c := l.input[i]
+	switch {
+	case isLetter(c):
+		return rune(c), 1
+	case isDec(c):
+		if first {
+			return 0, 0
+		}
+		return rune(c), 1
+	case c == '\\':
+		return l.identUCN(i, first)
+	case c < utf8.RuneSelf || l.enc != UTF8:
+		return 0, 0
+	}
+	if !utf8.FullRuneInString(l.input[i:]) {
+		// 文字の途中で入力が切れている
+		l.hitEnd = true
+		return 0, 0
+	}
+	r, n = utf8.DecodeRuneInString(l.input[i:])
+	if r == utf8.RuneError || !isIdentRune(r, first) {
+		return 0, 0
+	}
+	return r, n
+}
+
+// identUCN は識別子の中の \u, \U を読む
+func (l *Lexer) identUCN(i int, first bool) (rune, int) {
+	if i+1 >= len(l.input) {
+		l.hitEnd = true
+		return 0, 0
+	}
+	digits := 0
+	switch l.input[i+1] {
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	default:
+		return 0, 0
+	}
+	var v rune
+	for j := i + 2; j < i+2+digits; j++ {
+		if j >= len(l.input) {
+			l.hitEnd = true
+			return 0, 0
+		}
+		if !isHex(l.input[j]) {
+			return 0, 0
+		}
+		v = v<<4 | rune(hexValue(l.input[j]))
+	}
+	if v < 0xA0 {
+		// 基本文字集合の文字は \u, \U で書けない($, @, ` は識別子の文字ではない)
+		return 0, 0
+	}
+	if v > unicode.MaxRune || !isIdentRune(v, first) {
+		return 0, 0
+	}
+	return v, 2 + digits
+}
+
+// isIdentRune は r が識別子に使える文字か返す
+// XID_Start, XID_Continue を unicode パッケージの表で近似する
+func isIdentRune(r rune, first bool) bool {
+	if r < utf8.RuneSelf {
+		return r == '_' || isLetter(byte(r)) || !first && isDec(byte(r))
+	}
+	if unicode.Is(unicode.Pattern_Syntax, r) || unicode.Is(unicode.Pattern_White_Space, r) {
+		return false
+	}
+	if unicode.IsLetter(r) || unicode.Is(unicode.Nl, r) || unicode.Is(unicode.Other_ID_Start, r) {
+		return true
+	}
+	if first {
+		return false
+	}
+	return unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue)
+}
+
+// isIdentStart は l.input[i] から識別子が始まるか返す
+func (l *Lexer) isIdentStart(i int) bool {
+	_, n := l.identChar(i, true)
+	return n > 0
+}
+
+// readIdent は識別子を読み, \u, \U を UTF-8 にした名前を返す
+// ディレクティブモードでは識別子の途中の行の継続を取り除く
+func (l *Lexer) readIdent() string {
+	var b strings.Builder
+	next := l.pos
+	for next < len(l.input) {
+		if n := l.lineSplice(next); n > 0 && l.directives && next > l.pos {
+			if next+n >= len(l.input) {
+				l.hitEnd = true
+				break
+			}
+			if _, m := l.identChar(next+n, false); m == 0 {
+				break
+			}
+			next += n
+			continue
+		}
+		r, n := l.identChar(next, next == l.pos)
+		if n == 0 {
+			break
+		}
+		b.WriteRune(r)
+		next += n
+	}
+	if next == len(l.input) {
+		// 識別子が続いているかもしれない
+		l.hitEnd = true
+	}
+	l.pos = next
+	return b.String()
+}
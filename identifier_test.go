@@ -0,0 +1,145 @@
+package clanglex
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIdentifier(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  []Token
+	}{
+		{
+			"test utf-8 identifier",
+			"int café = 1;",
+			[]Token{
+				{TokenType: Word, Literal: "int"},
+				{TokenType: Word, Literal: "café", Raw: "café"},
+				{TokenType: Assign, Literal: "="},
+				{TokenType: Integer, Literal: "1"},
+				{TokenType: Semicolon, Literal: ";"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test ucn identifier",
+			`caf\u00E9 \U00003042x`,
+			[]Token{
+				{TokenType: Word, Literal: "café", Raw: `caf\u00E9`},
+				{TokenType: Word, Literal: "あx", Raw: `\U00003042x`},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test japanese identifier",
+			"変数1+値",
+			[]Token{
+				{TokenType: Word, Literal: "変数1", Raw: "変数1"},
+				{TokenType: Plus, Literal: "+"},
+				{TokenType: Word, Literal: "値", Raw: "値"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test combining mark continues",
+			"é",
+			[]Token{
+				{TokenType: Word, Literal: "é", Raw: "é"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test not ucn",
+			`\n`,
+			[]Token{
+				{TokenType: Backslash, Literal: `\`},
+				{TokenType: Word, Literal: "n", Raw: "n"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test ucn basic character",
+			`\u0069f x\u0041`,
+			[]Token{
+				{TokenType: Backslash, Literal: `\`},
+				{TokenType: Word, Literal: "u0069f", Raw: "u0069f"},
+				{TokenType: Word, Literal: "x", Raw: "x"},
+				{TokenType: Backslash, Literal: `\`},
+				{TokenType: Word, Literal: "u0041", Raw: "u0041"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+		{
+			"test ucn control",
+			`\u0080`,
+			[]Token{
+				{TokenType: Backslash, Literal: `\`},
+				{TokenType: Word, Literal: "u0080", Raw: "u0080"},
+				{TokenType: Eof, Literal: "eof"},
+			},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect) {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect))
+		}
+		for i, v := range got {
+			e := tt.expect[i]
+			if v.TokenType != e.TokenType || v.Literal != e.Literal {
+				t.Errorf("got=%v %q, expect=%v %q", v.TokenType, v.Literal, e.TokenType, e.Literal)
+			}
+			if e.Raw != "" && v.Raw != e.Raw {
+				t.Errorf("got raw=%q, expect raw=%q", v.Raw, e.Raw)
+			}
+		}
+	}
+}
+
+func TestIdentifierError(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+	}{
+		{"test digit start", "a ١"},
+		{"test combining mark start", "́a"},
+		{"test symbol", "a ∑"},
+		{"test symbol in identifier", "a∑"},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		_, err := Lexicalize(tt.src)
+		if !errors.Is(err, ErrUnknownChar) {
+			t.Errorf("got err=%v, expect %v", err, ErrUnknownChar)
+		}
+	}
+}
+
+func TestIdentifierReader(t *testing.T) {
+	// 識別子が読み込みの区切りをまたいでも1つのトークンになる
+	src := strings.Repeat("a", readChunk-3) + ` café x`
+	l := NewReaderLexer(strings.NewReader(src))
+	got := []string{}
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.TokenType == Eof {
+			break
+		}
+		got = append(got, tok.Literal)
+	}
+	if len(got) != 3 || got[1] != "café" || got[2] != "x" {
+		t.Errorf("got=%q", got[1:])
+	}
+}
@@ -0,0 +1,56 @@
+package clanglex
+
+import "strings"
+
+const (
+	// バイナリか調べる入力の先頭のバイト数
+	sniffLen = 8000
+	// 制御文字の割合で判定する最小のバイト数(短い入力の制御文字は ErrUnknownChar にする)
+	minSniffLen = 512
+	// 先頭の UTF-8 の BOM
+	bom = "\xEF\xBB\xBF"
+)
+
+// start は最初のトークンの前に入力の先頭を調べる
+// UTF-8 の BOM を読み飛ばし, バイナリなら ErrBinary を返す
+func (l *Lexer) start() error {
+	for l.r != nil && !l.eof && len(l.input) < sniffLen {
+		if err := l.fill(); err != nil {
+			return err
+		}
+	}
+	if off, ok := isBinary(l.input); ok {
+		return l.errorf(ErrBinary, off, "binary input is not C source")
+	}
+	if l.enc == UTF8 && strings.HasPrefix(l.input, bom) {
+		l.pos = len(bom)
+		l.bom = len(bom)
+	}
+	l.started = true
+	return nil
+}
+
+// isBinary は s の先頭がバイナリか調べ, 根拠になったバイトのオフセットを返す
+// NUL を含むか, minSniffLen バイト以上で空白以外の制御文字が1割を超えればバイナリとする
+func isBinary(s string) (int, bool) {
+	if len(s) > sniffLen {
+		s = s[:sniffLen]
+	}
+	first, ctrl := -1, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == 0 {
+			return i, true
+		}
+		if c < ' ' && c != '\t' && c != '\n' && c != '\v' && c != '\f' && c != '\r' || c == 0x7F {
+			if first < 0 {
+				first = i
+			}
+			ctrl++
+		}
+	}
+	if len(s) >= minSniffLen && ctrl*10 > len(s) {
+		return first, true
+	}
+	return 0, false
+}
@@ -0,0 +1,135 @@
+package clanglex
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBOM(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		reader  bool
+	}{
+		{"test bom", false},
+		{"test bom reader", true},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		src := bom + "int a;"
+		var l *Lexer
+		if tt.reader {
+			l = NewReaderLexer(strings.NewReader(src))
+		} else {
+			l = NewLexer(src)
+		}
+		got, err := l.lexicalize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 4 {
+			t.Fatalf("got len=%v, expect len=4", len(got))
+		}
+		if got[0].Literal != "int" || got[0].Raw != "int" {
+			t.Errorf("got literal=%q raw=%q", got[0].Literal, got[0].Raw)
+		}
+		expect := Position{"", 3, 1, 1, 1}
+		if got[0].Pos != expect {
+			t.Errorf("got pos=%+v, expect pos=%+v", got[0].Pos, expect)
+		}
+	}
+}
+
+func TestBOMOnlyAtStart(t *testing.T) {
+	_, err := Lexicalize("a " + bom)
+	if !errors.Is(err, ErrUnknownChar) {
+		t.Errorf("got err=%v, expect %v", err, ErrUnknownChar)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		binary  bool
+		offset  int
+	}{
+		{"test nul", "int a;\x00", true, 6},
+		{"test control bytes", "ab" + strings.Repeat("\x01\x02\x03\x04 a", 100), true, 2},
+		{"test short control bytes", "ab\x01\x02\x03\x04", false, 0},
+		{"test few control bytes long", strings.Repeat("int a;\n", 100) + "\x01", false, 0},
+		{"test few control bytes", "int a; /* \x0c */\x1b\n", false, 0},
+		{"test text", "int a;\n", false, 0},
+		{"test empty", "", false, 0},
+		{"test nul after sniff", strings.Repeat(" ", sniffLen) + "\x00", false, 0},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		off, ok := isBinary(tt.src)
+		if ok != tt.binary || off != tt.offset {
+			t.Errorf("got=%v %v, expect=%v %v", ok, off, tt.binary, tt.offset)
+		}
+	}
+}
+
+func TestBinaryError(t *testing.T) {
+	src := "int a;\x00"
+	_, err := Lexicalize(src, WithRecovery())
+	if !errors.Is(err, ErrBinary) {
+		t.Fatalf("got err=%v, expect %v", err, ErrBinary)
+	}
+	var le *LexError
+	if !errors.As(err, &le) || le.Pos.Offset != 6 {
+		t.Errorf("got err=%#v", err)
+	}
+
+	l := NewReaderLexer(strings.NewReader(src))
+	for i := 0; i < 2; i++ {
+		if _, err := l.Next(); !errors.Is(err, ErrBinary) {
+			t.Errorf("got err=%v, expect %v", err, ErrBinary)
+		}
+	}
+}
+
+func TestBOMDirectives(t *testing.T) {
+	got, err := Lexicalize(bom+"#define X\nX", WithDirectives())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Position{
+		{"", 3, 1, 1, 1},
+		{"", 4, 1, 2, 2},
+		{"", 11, 1, 9, 9},
+		{"", 12, 1, 10, 10},
+		{"", 13, 2, 1, 1},
+	}
+	for i, e := range expect {
+		if got[i].Pos != e {
+			t.Errorf("%q: got pos=%+v, expect pos=%+v", got[i].Literal, got[i].Pos, e)
+		}
+	}
+}
+
+func TestShortControlByte(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+	}{
+		{"test del", "int a; \x7f"},
+		{"test control", "a\x01"},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		_, err := Lexicalize(tt.src)
+		if !errors.Is(err, ErrUnknownChar) {
+			t.Errorf("got err=%v, expect %v", err, ErrUnknownChar)
+		}
+		ts, err := Lexicalize(tt.src, WithRecovery())
+		if !errors.Is(err, ErrUnknownChar) || ts[len(ts)-2].TokenType != Illegal {
+			t.Errorf("got err=%v tokens=%v", err, ts)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package clanglex
+
+// WithKeywords は Dialect のキーワードに kw を加える
+// 綴りは既存のトークンの種類か NewTokenType で割り当てた種類に対応させる
+// Dialect と同じ綴りがあれば kw を優先し, Word を指定するとキーワードでなくなる
+func WithKeywords(kw map[string]TokenType) Option {
+	return func(l *Lexer) {
+		if l.extraKeywords == nil {
+			l.extraKeywords = map[string]TokenType{}
+		}
+		for k, v := range kw {
+			l.extraKeywords[k] = v
+		}
+	}
+}
+
+// initKeywords はオプションを適用した後に字句解析で引くキーワードの表を決める
+func (l *Lexer) initKeywords() {
+	if l.dialect == nil {
+		l.dialect = legacy
+	}
+	if len(l.extraKeywords) == 0 {
+		l.keywords = l.dialect.keywords
+		return
+	}
+	l.keywords = make(map[string]TokenType, len(l.dialect.keywords)+len(l.extraKeywords))
+	for k, v := range l.dialect.keywords {
+		l.keywords[k] = v
+	}
+	for k, v := range l.extraKeywords {
+		if v == Word {
+			delete(l.keywords, k)
+			continue
+		}
+		l.keywords[k] = v
+	}
+}
+
+// Dialect を指定しない場合のキーワード(int や char は Word になる)
+var legacy = newDialect("legacy", nil, map[string]TokenType{
+	"return":        KeyReturn,
+	"if":            KeyIf,
+	"else":          KeyElse,
+	"while":         KeyWhile,
+	"do":            KeyDo,
+	"goto":          KeyGoto,
+	"for":           KeyFor,
+	"break":         KeyBreak,
+	"continue":      KeyContinue,
+	"switch":        KeySwitch,
+	"case":          KeyCase,
+	"default":       KeyDefault,
+	"extern":        KeyExtern,
+	"volatile":      KeyVolatile,
+	"const":         KeyConst,
+	"typedef":       KeyTypedef,
+	"union":         KeyUnion,
+	"struct":        KeyStruct,
+	"enum":          KeyEnum,
+	"__attribute__": KeyAttribute,
+	"void":          KeyVoid,
+	"__asm":         KeyAsm,
+	"sizeof":        KeySizeof,
+	"static":        KeyStatic,
+})
@@ -0,0 +1,86 @@
+package clanglex
+
+import "testing"
+
+func TestWithKeywords(t *testing.T) {
+	keyFunc := NewTokenType("KeyFunc", CategoryKeyword)
+
+	testTbl := []struct {
+		comment string
+		src     string
+		opts    []Option
+		expect  []TokenType
+	}{
+		{
+			"test custom token type",
+			"FUNC(void, APP_CODE) far f",
+			[]Option{WithKeywords(map[string]TokenType{"FUNC": keyFunc, "far": KeyFar})},
+			[]TokenType{keyFunc, Lparen, KeyVoid, Comma, Word, Rparen, KeyFar, Word},
+		},
+		{
+			"test with dialect",
+			"int far x",
+			[]Option{WithKeywords(map[string]TokenType{"far": KeyFar}), WithDialect(C99)},
+			[]TokenType{KeyInt, KeyFar, Word},
+		},
+		{
+			"test override dialect",
+			"inline restrict int",
+			[]Option{WithDialect(C99), WithKeywords(map[string]TokenType{"inline": Word, "restrict": KeyVolatile})},
+			[]TokenType{Word, KeyVolatile, KeyInt},
+		},
+		{
+			"test override legacy",
+			"int static",
+			[]Option{WithKeywords(map[string]TokenType{"int": KeyInt, "static": Word})},
+			[]TokenType{KeyInt, Word},
+		},
+		{
+			"test multiple options",
+			"FUNC far",
+			[]Option{WithKeywords(map[string]TokenType{"FUNC": keyFunc}), WithKeywords(map[string]TokenType{"far": KeyFar})},
+			[]TokenType{keyFunc, KeyFar},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src, tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect)+1 {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect)+1)
+		}
+		for i, e := range tt.expect {
+			if got[i].TokenType != e {
+				t.Errorf("%s: got type=%v, expect type=%v", got[i].Literal, got[i].TokenType, e)
+			}
+		}
+	}
+
+	// Dialect は変更しない
+	if C99.IsKeyword("FUNC") || !C99.IsKeyword("inline") {
+		t.Errorf("WithKeywords must not modify the dialect")
+	}
+}
+
+func TestNewTokenType(t *testing.T) {
+	a := NewTokenType("KeyA", CategoryKeyword)
+	b := NewTokenType("KeyB", CategoryKeyword)
+	if a < numTokenTypes || a == b {
+		t.Fatalf("got a=%v b=%v", a, b)
+	}
+	tk := &Token{TokenType: b, Literal: "b"}
+	if tk.String() != "TokenType:KeyB, Literal:b" {
+		t.Errorf("got=%q", tk.String())
+	}
+}
+
+func BenchmarkDetermineKeyword(b *testing.B) {
+	l := NewLexer("", WithDialect(GNU11), WithKeywords(map[string]TokenType{"FUNC": KeyAttribute}))
+	words := []string{"int", "g_var", "return", "__attribute__", "FUNC", "x"}
+	for i := 0; i < b.N; i++ {
+		l.determineKeyword(words[i%len(words)])
+	}
+}
@@ -2,22 +2,64 @@ package clanglex
 
 import (
 	"fmt"
-	"strconv"
+	"io"
 	"strings"
 )
 
 type Lexer struct {
-	input string
-	pos   int
+	input   string
+	pos     int
+	file    string
+	cur     Position
+	dialect *Dialect
+	// 字句解析で引くキーワードの表(dialect と extraKeywords を合わせたもの)
+	keywords      map[string]TokenType
+	extraKeywords map[string]TokenType
+	marker        *linemarker
+
+	userOnly       bool
+	systemPrefixes []string
+
+	directives bool
+	dir        directiveState
+
+	recovery bool
+	errs     ErrorList
+
+	// 次のトークンは __asm の後ろ
+	asmNext bool
+
+	// ソースの文字コード
+	enc Encoding
+
+	// 入力の先頭を調べ終わった
+	started bool
+	// 先頭の BOM のバイト数(列に数えない)
+	bom int
+
+	// io.Reader から読む場合の状態
+	r      io.Reader
+	chunk  int
+	eof    bool
+	base   int
+	hitEnd bool
 }
 
 type Token struct {
-	TokenType int
+	TokenType TokenType
 	Literal   string
+	Pos       Position // トークン先頭の位置
+	End       Position // トークン末尾の次の位置
+	Origin    Origin   // ラインマーカから求めた元ファイルでの位置
+	Raw       string   // ソース上の綴り
+	Prefix    string   // 文字列, 文字リテラルの接頭辞 (L, u, U, u8)
+	Pieces    []*Token // ConcatStrings でまとめた文字列リテラルの元のトークン
+
+	enc Encoding // 文字列, 文字リテラルの文字コード
 }
 
 const (
-	Eof = iota
+	Eof TokenType = iota
 	Word
 	Integer
 	Float
@@ -70,6 +112,9 @@ const (
 	TildeAssigne
 	CaretAssigne
 	PercentAssigne
+	At
+	Hash
+	HashHash
 	KeyReturn
 	KeyIf
 	KeyElse
@@ -94,186 +139,108 @@ const (
 	KeyAsm
 	KeySizeof
 	KeyStatic
+	KeyInt
+	KeyChar
+	KeyShort
+	KeyLong
+	KeySigned
+	KeyUnsigned
+	KeyFloat
+	KeyDouble
+	KeyRegister
+	KeyAuto
+	KeyInline
+	KeyRestrict
+	KeyBool
+	KeyComplex
+	KeyImaginary
+	KeyAlignas
+	KeyAlignof
+	KeyAtomic
+	KeyGeneric
+	KeyNoreturn
+	KeyStaticAssert
+	KeyThreadLocal
+	KeyTrue
+	KeyFalse
+	KeyNullptr
+	KeyConstexpr
+	KeyTypeof
+	KeyTypeofUnqual
+	KeyBitInt
+	KeyDecimal32
+	KeyDecimal64
+	KeyDecimal128
+	KeyExtension
+	KeyLabel
+	KeyAutoType
+	KeyInt128
+	KeyBuiltinVaList
+	KeyPragma
+	KeyInterrupt
+	KeyNear
+	KeyFar
+	KeyHuge
+	KeyNoInit
+	KeyRamfunc
+	KeyRoot
+	KeyIntrinsic
+	KeyMonitor
+	KeyTask
+	KeyPacked
+	KeyWeak
+	KeyEvenaccess
+	KeySaddr
+	KeyCallt
+	KeyCregister
+	KeyMemoryType
+	KeyBit
+	KeySbit
+	KeySfr
+	KeySfr16
+	KeyUsing
+	KeyReentrant
+	KeyAt
+	KeyDeclspec
+	KeyInt8
+	KeyInt16
+	KeyInt32
+	KeyInt64
+	KeyCdecl
+	KeyStdcall
+	KeyFastcall
+	KeyThiscall
+	KeyVectorcall
+	KeyForceinline
+	Directive
+	HeaderName
+	EndDirective
+	AsmBlock
 	Comment
 	Illegal
+
+	// 組込みのトークンの種類の数(NewTokenType はこれ以降を割り当てる)
+	numTokenTypes
 )
 
 func (t *Token) String() string {
-	tts := ""
-	switch t.TokenType {
-	case Eof:
-		tts = "Eof"
-	case Word:
-		tts = "Word"
-	case Integer:
-		tts = "Integer"
-	case Float:
-		tts = "Float"
-	case Assign:
-		tts = "Assign"
-	case Plus:
-		tts = "Plus"
-	case Minus:
-		tts = "Minus"
-	case Bang:
-		tts = "Bang"
-	case Asterisk:
-		tts = "Asterisk"
-	case Slash:
-		tts = "Slash"
-	case Percent:
-		tts = "Percent"
-	case Lt:
-		tts = "Lt"
-	case Gt:
-		tts = "Gt"
-	case Eq:
-		tts = "Eq"
-	case Ne:
-		tts = "Ne"
-	case Gteq:
-		tts = "Gteq"
-	case Lteq:
-		tts = "Lteq"
-	case Semicolon:
-		tts = "Semicolon"
-	case Lparen:
-		tts = "Lparen"
-	case Rparen:
-		tts = "Rparen"
-	case Comma:
-		tts = "Comma"
-	case Lbrace:
-		tts = "Lbrace"
-	case Rbrace:
-		tts = "Rbrace"
-	case Lbracket:
-		tts = "Lbracket"
-	case Rbracket:
-		tts = "Rbracket"
-	case Ampersand:
-		tts = "Ampersand"
-	case Tilde:
-		tts = "Tilde"
-	case Caret:
-		tts = "Caret"
-	case Vertical:
-		tts = "Vertical"
-	case Colon:
-		tts = "Colon"
-	case Question:
-		tts = "Question"
-	case Period:
-		tts = "Period"
-	case Backslash:
-		tts = "Backslash"
-	case Str:
-		tts = "Str"
-	case Letter:
-		tts = "Letter"
-	case Arrow:
-		tts = "Arrow"
-	case LeftShift:
-		tts = "LeftShift"
-	case RightShift:
-		tts = "RightShift"
-	case Increment:
-		tts = "Increment"
-	case Decrement:
-		tts = "Decrement"
-	case And:
-		tts = "And"
-	case Or:
-		tts = "Or"
-	case PlusAssigne:
-		tts = "PlusAssigne"
-	case MinusAssigne:
-		tts = "MinusAssigne"
-	case AsteriskAssigne:
-		tts = "AsteriskAssigne"
-	case SlashAssigne:
-		tts = "SlashAssigne"
-	case VerticalAssigne:
-		tts = "VerticalAssigne"
-	case AmpersandAssigne:
-		tts = "AmpersandAssigne"
-	case LeftShiftAssigne:
-		tts = "LeftShiftAssigne"
-	case RightShiftAssigne:
-		tts = "RightShiftAssigne"
-	case TildeAssigne:
-		tts = "TildeAssigne"
-	case CaretAssigne:
-		tts = "CaretAssigne"
-	case PercentAssigne:
-		tts = "PercentAssigne"
-	case KeyReturn:
-		tts = "KeyReturn"
-	case KeyIf:
-		tts = "KeyIf"
-	case KeyElse:
-		tts = "KeyElse"
-	case KeyWhile:
-		tts = "KeyWhile"
-	case KeyDo:
-		tts = "KeyDo"
-	case KeyGoto:
-		tts = "KeyGoto"
-	case KeyFor:
-		tts = "KeyFor"
-	case KeyBreak:
-		tts = "KeyBreak"
-	case KeyContinue:
-		tts = "KeyContinue"
-	case KeySwitch:
-		tts = "KeySwitch"
-	case KeyCase:
-		tts = "KeyCase"
-	case KeyDefault:
-		tts = "KeyDefault"
-	case KeyExtern:
-		tts = "KeyExtern"
-	case KeyVolatile:
-		tts = "KeyVolatile"
-	case KeyConst:
-		tts = "KeyConst"
-	case KeyTypedef:
-		tts = "KeyTypedef"
-	case KeyUnion:
-		tts = "KeyUnion"
-	case KeyStruct:
-		tts = "KeyStruct"
-	case KeyEnum:
-		tts = "KeyEnum"
-	case KeyAttribute:
-		tts = "KeyAttribute"
-	case KeyVoid:
-		tts = "KeyVoid"
-	case KeyAsm:
-		tts = "KeyAsm"
-	case KeySizeof:
-		tts = "KeySizeof"
-	case KeyStatic:
-		tts = "KeyStatic"
-	case Comment:
-		tts = "Comment"
-	case Illegal:
-		tts = "Illegal"
-	default:
-		tts = strconv.Itoa(t.TokenType)
-	}
-
-	return fmt.Sprintf("TokenType:%v, Literal:%s", tts, t.Literal)
+	return fmt.Sprintf("TokenType:%v, Literal:%s", t.TokenType, t.Literal)
 }
 
-func NewLexer(src string) *Lexer {
-	return &Lexer{input: src, pos: 0}
+func NewLexer(src string, opts ...Option) *Lexer {
+	l := &Lexer{input: src, pos: 0, cur: Position{Line: 1, Column: 1, RuneColumn: 1}}
+	l.dir.lineStart = true
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.initKeywords()
+	return l
 }
 
 func (l *Lexer) lexicalize() ([]*Token, error) {
 	ts := []*Token{}
 	for {
-		t, err := l.nextToken()
+		t, err := l.Next()
 		if err != nil {
 			return nil, err
 		}
@@ -282,29 +249,60 @@ func (l *Lexer) lexicalize() ([]*Token, error) {
 			break
 		}
 	}
+	if len(l.errs) > 0 {
+		return ts, l.errs
+	}
 	return ts, nil
 }
 
 func (l *Lexer) nextToken() (*Token, error) {
 	// スペースをとばす
+	nl := false
 	for {
 		i := l.pos
 		if i >= len(l.input) {
 			break
 		}
 		c := l.input[i]
+		if c == '\n' {
+			nl = true
+		}
+		if l.directives {
+			if n := l.lineSplice(i); n > 0 {
+				l.pos += n
+				continue
+			}
+			if c == '\n' {
+				if l.dir.in {
+					// ディレクティブの終わり
+					break
+				}
+				l.dir.lineStart = true
+			}
+		}
 		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
 			break
 		}
 		l.pos++
 	}
 
+	if l.dir.in && (l.pos >= len(l.input) || l.input[l.pos] == '\n') {
+		return l.endDirective(), nil
+	}
+
 	// ソースの終端
 	if l.pos >= len(l.input) {
-		return &Token{TokenType: Eof, Literal: "eof"}, nil
+		p := l.position(len(l.input))
+		return &Token{TokenType: Eof, Literal: "eof", Pos: p, End: p, Origin: l.origin(p.Line)}, nil
 	}
 
+	start := l.pos
+
 	var tk *Token
+	isHash := false
+	dir := l.dir
+	asm := l.asmNext
+	l.asmNext = false
 	c := l.input[l.pos]
 	switch c {
 	case '=':
@@ -368,10 +366,23 @@ func (l *Lexer) nextToken() (*Token, error) {
 		} else if l.input[l.pos] == '*' {
 			// comment
 			l.pos++
-			com := l.readComment()
+			com, err := l.readComment()
+			if err != nil {
+				return nil, err
+			}
+			tk = &Token{TokenType: Comment, Literal: com}
+		} else if l.input[l.pos] == '/' {
+			// line comment
+			l.pos++
+			com := l.readLineComment()
 			tk = &Token{TokenType: Comment, Literal: com}
 		}
 	case '<':
+		if dir.expectHeader {
+			if tk = l.readHeaderName(); tk != nil {
+				break
+			}
+		}
 		tk = &Token{TokenType: Lt, Literal: "<"}
 		l.pos++
 		if l.pos >= len(l.input) {
@@ -416,6 +427,14 @@ func (l *Lexer) nextToken() (*Token, error) {
 		tk = &Token{TokenType: Comma, Literal: ","}
 		l.pos++
 	case '{':
+		if asm {
+			// __asm { ... }
+			var err error
+			if tk, err = l.readAsmBlock(); err != nil {
+				return nil, err
+			}
+			break
+		}
 		tk = &Token{TokenType: Lbrace, Literal: "{"}
 		l.pos++
 	case '}':
@@ -479,119 +498,154 @@ func (l *Lexer) nextToken() (*Token, error) {
 	case '?':
 		tk = &Token{TokenType: Question, Literal: "?"}
 		l.pos++
+	case '@':
+		if !l.dialect.Has(FeatureAtSign) {
+			return nil, l.errorf(ErrUnknownChar, l.pos, "unknown character %s", l.charName(l.pos))
+		}
+		// 絶対番地の指定 (int reg @ 0xFFFF0000;)
+		tk = &Token{TokenType: At, Literal: "@"}
+		l.pos++
 	case '.':
+		if l.pos+1 < len(l.input) && isDec(l.input[l.pos+1]) {
+			// .5
+			var err error
+			if tk, err = l.readNumber(); err != nil {
+				return nil, err
+			}
+			break
+		}
 		tk = &Token{TokenType: Period, Literal: "."}
 		l.pos++
 	case '\\':
+		if l.isIdentStart(l.pos) {
+			// \u00E9 などで始まる識別子
+			tk = l.readWord()
+			break
+		}
 		tk = &Token{TokenType: Backslash, Literal: "\\"}
 		l.pos++
 	case '\'':
-		tk = l.readLetter()
+		var err error
+		if tk, err = l.readLetter(""); err != nil {
+			return nil, err
+		}
 	case '"':
-		tk = l.readString()
+		var err error
+		if tk, err = l.readString(""); err != nil {
+			return nil, err
+		}
 	case '#':
-		tk = l.readHashComment()
-		l.pos++
+		if l.directives {
+			tk = l.readHash()
+		} else {
+			tk = l.readHashComment()
+			isHash = true
+		}
 	default:
-		if isLetter(c) {
+		if isLetter(c) && dir.expectName {
+			tk = l.readDirectiveName()
+		} else if isLetter(c) && asm && !nl {
+			// __asm mov eax, 1
+			tk = l.readAsmLine()
+		} else if n := l.encodingPrefix(); n > 0 && l.input[l.pos+n] == '"' {
+			// L"abc" など
+			var err error
+			if tk, err = l.readString(l.input[l.pos : l.pos+n]); err != nil {
+				return nil, err
+			}
+		} else if n > 0 {
+			// L'a' など
+			var err error
+			if tk, err = l.readLetter(l.input[l.pos : l.pos+n]); err != nil {
+				return nil, err
+			}
+		} else if l.isIdentStart(l.pos) {
 			tk = l.readWord()
 		} else if isDec(c) {
-			tk = l.readNumber()
+			var err error
+			if tk, err = l.readNumber(); err != nil {
+				return nil, err
+			}
 		} else {
-			return nil, fmt.Errorf("unknown character '%c'", c)
+			return nil, l.errorf(ErrUnknownChar, l.pos, "unknown character %s", l.charName(l.pos))
 		}
 	}
+	if tk.TokenType != Comment {
+		l.dir.lineStart = false
+	}
+	if dir.expectName {
+		l.dir.expectName = false
+	}
+	if dir.expectHeader {
+		l.dir.expectHeader = false
+	}
+	tk.Raw = l.input[start:l.pos]
+	tk.Pos = l.position(start)
+	tk.End = l.position(l.pos)
+	if isHash && l.applyLinemarker(tk.Literal, tk.Pos.Line) {
+		// ラインマーカ自身はマーカが示す位置とする
+		tk.Origin = l.origin(tk.Pos.Line + 1)
+	} else {
+		tk.Origin = l.origin(tk.Pos.Line)
+	}
 	return tk, nil
 }
 
 func (l *Lexer) readWord() *Token {
 	// ワードの終わりの次まで pos を進める
-	var next int
-	for next = l.pos; next < len(l.input); next++ {
-		c := l.input[next]
-		if !isLetter(c) && !isDec(c) {
-			break
-		}
+	tk := l.determineKeyword(l.readIdent())
+	if tk.TokenType == KeyAsm && l.dialect.Has(FeatureMSAsmBlock) {
+		l.asmNext = true
 	}
-	w := l.input[l.pos:next]
-	tk := l.determineKeyword(w)
-	l.pos = next
 	return tk
 }
 
-func (l *Lexer) readNumber() *Token {
-	var next int
-	isFloat := false
-
-	next = l.pos
-	c := l.input[next]
-	if c == '0' {
-		next++
-		c = l.input[next]
-		switch c {
-		case 'x':
-			// 16進数
-			next++
-		case 'b':
-			// 2進数
-			next++
-		case '.':
-			// 小数
-			next++
-			isFloat = true
-		default:
-			if isDec(c) {
-				// 8進数
-				next++
-			} else {
-				// ゼロ
-
-			}
-		}
+// readString は接頭辞 prefix の付いた文字列リテラルを読む
+// Literal は接頭辞を除き, 引用符を含む
+func (l *Lexer) readString(prefix string) (*Token, error) {
+	quote := l.pos + len(prefix)
+	next, err := l.findQuote(quote, '"')
+	if err != nil {
+		return nil, err
 	}
-
-	// ワードの終わりの次まで pos を進める
-	for ; next < len(l.input); next++ {
-		c = l.input[next]
-		if c == '.' {
-			isFloat = true
-		} else if c == 'u' || c == 'U' || c == 'l' || c == 'L' {
-			continue
-		} else if !isHex(c) {
-			break
-		}
+	if _, err := splitChars(l.input[quote+1:next], l.enc); err != nil {
+		return nil, l.errorf(ErrBadEscape, l.pos, "%v", err)
 	}
-	w := l.input[l.pos:next]
+	// 次の pos に進める
+	next++
+	w := l.input[quote:next]
 	l.pos = next
-
-	var tk *Token
-	if isFloat {
-		tk = &Token{TokenType: Float, Literal: w}
-	} else {
-		tk = &Token{TokenType: Integer, Literal: w}
-	}
-	return tk
+	return &Token{TokenType: Str, Literal: w, Prefix: prefix, enc: l.enc}, nil
 }
 
-func (l *Lexer) readString() *Token {
-	var next int
-
-	// 次の " を探す
-	for next = l.pos + 1; next < len(l.input); next++ {
-		// エスケープシーケンス考慮
-		if l.input[next] == '\\' && l.input[next+1] == '\\' {
-			next++
-		} else if l.input[next] == '\\' && l.input[next+1] == '"' {
+// findQuote は位置 at の引用符に対応する閉じ引用符 q の位置を返す
+// 閉じ引用符の前に改行か入力の終わりが来たらトークンの先頭の位置のエラーを返す
+func (l *Lexer) findQuote(at int, q byte) (int, error) {
+	kind := ErrUnterminatedString
+	msg := "missing terminating '\"' character"
+	if q == '\'' {
+		kind = ErrUnterminatedChar
+		msg = "missing terminating ' character"
+	}
+	for next := at + 1; next < len(l.input); next++ {
+		c := l.input[next]
+		if n := l.enc.charLen(l.input, next); n > 1 {
+			// マルチバイト文字
+			next += n - 1
+		} else if c == '\\' {
+			// エスケープシーケンス考慮
 			next++
-		} else if l.input[next] == '"' {
-			break
+			if n := l.lineSplice(next - 1); n == 3 {
+				next++
+			}
+		} else if c == q {
+			return next, nil
+		} else if c == '\n' {
+			return 0, l.errorf(kind, l.pos, msg)
 		}
 	}
-	// 次の pos に進める
-	next++
-	w := l.input[l.pos:next]
-	l.pos = next
-	return &Token{TokenType: Str, Literal: w}
+	l.hitEnd = true
+	return 0, l.errorf(kind, l.pos, msg)
 }
 
 func (l *Lexer) readHashComment() *Token {
@@ -613,103 +667,50 @@ func (l *Lexer) readHashComment() *Token {
 	return tk
 }
 
-func (l *Lexer) readLetter() *Token {
-
-	l.pos++
-	var s []byte
-	c := l.input[l.pos]
-	if c == '\\' {
-		s = l.getEscC()
-		l.pos++
-	} else {
-		s = append(s, c)
-		l.pos++
-		l.pos++
+// readLetter は接頭辞 prefix の付いた文字リテラルを読む
+// Literal は接頭辞を除き, 引用符を含む
+func (l *Lexer) readLetter(prefix string) (*Token, error) {
+	quote := l.pos + len(prefix)
+	next, err := l.findQuote(quote, '\'')
+	if err != nil {
+		return nil, err
 	}
-	return &Token{TokenType: Letter, Literal: string(s)}
-}
-
-func (l *Lexer) getEscC() []byte {
-	res := []byte{}
-	res = append(res, l.input[l.pos])
-	l.pos++
-	if l.input[l.pos] >= '0' && l.input[l.pos] <= '9' {
-		for l.input[l.pos] >= '0' && l.input[l.pos] <= '9' {
-			res = append(res, l.input[l.pos])
-			l.pos++
-		}
-	} else {
-		res = append(res, l.input[l.pos])
-		l.pos++
+	if err := l.checkChar(quote, next); err != nil {
+		return nil, err
 	}
-
-	return res
+	w := l.input[quote : next+1]
+	l.pos = next + 1
+	return &Token{TokenType: Letter, Literal: w, Prefix: prefix, enc: l.enc}, nil
 }
 
-func (l *Lexer) newIllegal() *Token {
-	tk := &Token{TokenType: Illegal, Literal: l.input[l.pos:]}
-	l.pos = len(l.input)
+// newIllegal は現在位置から end の手前までを Illegal トークンにする
+func (l *Lexer) newIllegal(end int) *Token {
+	tk := &Token{TokenType: Illegal, Literal: l.input[l.pos:end], Raw: l.input[l.pos:end]}
+	tk.Pos = l.position(l.pos)
+	tk.End = l.position(end)
+	tk.Origin = l.origin(tk.Pos.Line)
+	l.pos = end
+	l.dir.lineStart = false
 	return tk
 }
 
 func (l *Lexer) determineKeyword(w string) *Token {
-	if strings.Compare("return", w) == 0 {
-		return &Token{TokenType: KeyReturn, Literal: w}
-	} else if strings.Compare("if", w) == 0 {
-		return &Token{TokenType: KeyIf, Literal: w}
-	} else if strings.Compare("else", w) == 0 {
-		return &Token{TokenType: KeyElse, Literal: w}
-	} else if strings.Compare("while", w) == 0 {
-		return &Token{TokenType: KeyWhile, Literal: w}
-	} else if strings.Compare("do", w) == 0 {
-		return &Token{TokenType: KeyDo, Literal: w}
-	} else if strings.Compare("goto", w) == 0 {
-		return &Token{TokenType: KeyGoto, Literal: w}
-	} else if strings.Compare("for", w) == 0 {
-		return &Token{TokenType: KeyFor, Literal: w}
-	} else if strings.Compare("break", w) == 0 {
-		return &Token{TokenType: KeyBreak, Literal: w}
-	} else if strings.Compare("continue", w) == 0 {
-		return &Token{TokenType: KeyContinue, Literal: w}
-	} else if strings.Compare("switch", w) == 0 {
-		return &Token{TokenType: KeySwitch, Literal: w}
-	} else if strings.Compare("case", w) == 0 {
-		return &Token{TokenType: KeyCase, Literal: w}
-	} else if strings.Compare("default", w) == 0 {
-		return &Token{TokenType: KeyDefault, Literal: w}
-	} else if strings.Compare("extern", w) == 0 {
-		return &Token{TokenType: KeyExtern, Literal: w}
-	} else if strings.Compare("volatile", w) == 0 {
-		return &Token{TokenType: KeyVolatile, Literal: w}
-	} else if strings.Compare("const", w) == 0 {
-		return &Token{TokenType: KeyConst, Literal: w}
-	} else if strings.Compare("typedef", w) == 0 {
-		return &Token{TokenType: KeyTypedef, Literal: w}
-	} else if strings.Compare("union", w) == 0 {
-		return &Token{TokenType: KeyUnion, Literal: w}
-	} else if strings.Compare("struct", w) == 0 {
-		return &Token{TokenType: KeyStruct, Literal: w}
-	} else if strings.Compare("enum", w) == 0 {
-		return &Token{TokenType: KeyEnum, Literal: w}
-	} else if strings.Compare("__attribute__", w) == 0 {
-		return &Token{TokenType: KeyAttribute, Literal: w}
-	} else if strings.Compare("void", w) == 0 {
-		return &Token{TokenType: KeyVoid, Literal: w}
-	} else if strings.Compare("__asm", w) == 0 {
-		return &Token{TokenType: KeyAsm, Literal: w}
-	} else if strings.Compare("sizeof", w) == 0 {
-		return &Token{TokenType: KeySizeof, Literal: w}
-	} else if strings.Compare("static", w) == 0 {
-		return &Token{TokenType: KeyStatic, Literal: w}
-	} else {
-		return &Token{TokenType: Word, Literal: w}
+	if tt, ok := l.keywords[w]; ok {
+		return &Token{TokenType: tt, Literal: w}
 	}
+	return &Token{TokenType: Word, Literal: w}
 }
 
-func (l *Lexer) readComment() string {
+func (l *Lexer) readComment() (string, error) {
+	// /* の位置
+	start := l.pos - 2
 	res := []byte{}
 
 	for !l.isCommentEnd() {
+		if l.pos >= len(l.input) {
+			l.hitEnd = true
+			return "", l.errorf(ErrUnterminatedComment, start, "unterminated comment")
+		}
 		res = append(res, l.input[l.pos])
 		l.pos++
 	}
@@ -717,12 +718,43 @@ func (l *Lexer) readComment() string {
 	l.pos++
 	// next
 
-	return string(res)
+	return string(res), nil
+}
+
+// readLineComment は // から行末までを読む
+// 行末の \ による行の継続は1つのコメントとする
+func (l *Lexer) readLineComment() string {
+	start := l.pos
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if n := l.enc.charLen(l.input, l.pos); n > 1 {
+			// マルチバイト文字の2バイト目は \ でも行の継続にしない
+			l.pos += n
+			continue
+		}
+		if c == '\\' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '\n' {
+			l.pos += 2
+			continue
+		}
+		if c == '\\' && l.pos+2 < len(l.input) && l.input[l.pos+1] == '\r' && l.input[l.pos+2] == '\n' {
+			l.pos += 3
+			continue
+		}
+		if c == '\n' {
+			break
+		}
+		l.pos++
+	}
+	end := l.pos
+	if end > start && l.input[end-1] == '\r' {
+		end--
+	}
+	return l.input[start:end]
 }
 
 func (l *Lexer) isCommentEnd() bool {
 	// */ か確認
-	return l.input[l.pos] == '*' && l.input[l.pos+1] == '/'
+	return l.pos+1 < len(l.input) && l.input[l.pos] == '*' && l.input[l.pos+1] == '/'
 }
 
 func isLetter(c byte) bool {
@@ -749,6 +781,30 @@ func (t *Token) IsTypeToken() bool {
 	case KeyUnion:
 	case KeyEnum:
 	case KeyVolatile:
+	case KeyInt:
+	case KeyChar:
+	case KeyShort:
+	case KeyLong:
+	case KeySigned:
+	case KeyUnsigned:
+	case KeyFloat:
+	case KeyDouble:
+	case KeyBool:
+	case KeyComplex:
+	case KeyImaginary:
+	case KeyRestrict:
+	case KeyAtomic:
+	case KeyBitInt:
+	case KeyDecimal32:
+	case KeyDecimal64:
+	case KeyDecimal128:
+	case KeyAutoType:
+	case KeyInt128:
+	case KeyBuiltinVaList:
+	case KeyInt8:
+	case KeyInt16:
+	case KeyInt32:
+	case KeyInt64:
 	case Caret:
 		// clang でコンパイルした場合型の種類に^が含まれる？
 	default:
@@ -848,6 +904,16 @@ func (t *Token) IsCompoundOp() bool {
 }
 
 // IsToken
-func (t *Token) IsToken(t2 int) bool {
+func (t *Token) IsToken(t2 TokenType) bool {
 	return t.TokenType == t2
 }
+
+// IsLineComment は // 形式のコメントか返す
+func (t *Token) IsLineComment() bool {
+	return t.TokenType == Comment && strings.HasPrefix(t.Raw, "//")
+}
+
+// IsBlockComment は /* */ 形式のコメントか返す
+func (t *Token) IsBlockComment() bool {
+	return t.TokenType == Comment && strings.HasPrefix(t.Raw, "/*")
+}
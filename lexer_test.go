@@ -1,6 +1,7 @@
 package clanglex
 
 import (
+	"errors"
 	_ "reflect"
 	"testing"
 )
@@ -16,8 +17,8 @@ func TestLexicalize(t *testing.T) {
 			``,
 			[]*Token{
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -26,12 +27,12 @@ func TestLexicalize(t *testing.T) {
 			`   char   `,
 			[]*Token{
 				{
-					Word,
-					"char",
+					TokenType: Word,
+					Literal:   "char",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -40,16 +41,16 @@ func TestLexicalize(t *testing.T) {
 			`   char		hoge   `,
 			[]*Token{
 				{
-					Word,
-					"char",
+					TokenType: Word,
+					Literal:   "char",
 				},
 				{
-					Word,
-					"hoge",
+					TokenType: Word,
+					Literal:   "hoge",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -58,12 +59,12 @@ func TestLexicalize(t *testing.T) {
 			`=`,
 			[]*Token{
 				{
-					Assign,
-					"=",
+					TokenType: Assign,
+					Literal:   "=",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -72,72 +73,72 @@ func TestLexicalize(t *testing.T) {
 			`=+-!*/<>;(),{}[]`,
 			[]*Token{
 				{
-					Assign,
-					"=",
+					TokenType: Assign,
+					Literal:   "=",
 				},
 				{
-					Plus,
-					"+",
+					TokenType: Plus,
+					Literal:   "+",
 				},
 				{
-					Minus,
-					"-",
+					TokenType: Minus,
+					Literal:   "-",
 				},
 				{
-					Bang,
-					"!",
+					TokenType: Bang,
+					Literal:   "!",
 				},
 				{
-					Asterisk,
-					"*",
+					TokenType: Asterisk,
+					Literal:   "*",
 				},
 				{
-					Slash,
-					"/",
+					TokenType: Slash,
+					Literal:   "/",
 				},
 				{
-					Lt,
-					"<",
+					TokenType: Lt,
+					Literal:   "<",
 				},
 				{
-					Gt,
-					">",
+					TokenType: Gt,
+					Literal:   ">",
 				},
 				{
-					Semicolon,
-					";",
+					TokenType: Semicolon,
+					Literal:   ";",
 				},
 				{
-					Lparen,
-					"(",
+					TokenType: Lparen,
+					Literal:   "(",
 				},
 				{
-					Rparen,
-					")",
+					TokenType: Rparen,
+					Literal:   ")",
 				},
 				{
-					Comma,
-					",",
+					TokenType: Comma,
+					Literal:   ",",
 				},
 				{
-					Lbrace,
-					"{",
+					TokenType: Lbrace,
+					Literal:   "{",
 				},
 				{
-					Rbrace,
-					"}",
+					TokenType: Rbrace,
+					Literal:   "}",
 				},
 				{
-					Lbracket,
-					"[",
+					TokenType: Lbracket,
+					Literal:   "[",
 				},
 				{
-					Rbracket,
-					"]",
+					TokenType: Rbracket,
+					Literal:   "]",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -146,76 +147,76 @@ func TestLexicalize(t *testing.T) {
 			` = + - ! * / % < > ; ( ) , { } [ ] `,
 			[]*Token{
 				{
-					Assign,
-					"=",
+					TokenType: Assign,
+					Literal:   "=",
 				},
 				{
-					Plus,
-					"+",
+					TokenType: Plus,
+					Literal:   "+",
 				},
 				{
-					Minus,
-					"-",
+					TokenType: Minus,
+					Literal:   "-",
 				},
 				{
-					Bang,
-					"!",
+					TokenType: Bang,
+					Literal:   "!",
 				},
 				{
-					Asterisk,
-					"*",
+					TokenType: Asterisk,
+					Literal:   "*",
 				},
 				{
-					Slash,
-					"/",
+					TokenType: Slash,
+					Literal:   "/",
 				},
 				{
-					Percent,
-					"%",
+					TokenType: Percent,
+					Literal:   "%",
 				},
 				{
-					Lt,
-					"<",
+					TokenType: Lt,
+					Literal:   "<",
 				},
 				{
-					Gt,
-					">",
+					TokenType: Gt,
+					Literal:   ">",
 				},
 				{
-					Semicolon,
-					";",
+					TokenType: Semicolon,
+					Literal:   ";",
 				},
 				{
-					Lparen,
-					"(",
+					TokenType: Lparen,
+					Literal:   "(",
 				},
 				{
-					Rparen,
-					")",
+					TokenType: Rparen,
+					Literal:   ")",
 				},
 				{
-					Comma,
-					",",
+					TokenType: Comma,
+					Literal:   ",",
 				},
 				{
-					Lbrace,
-					"{",
+					TokenType: Lbrace,
+					Literal:   "{",
 				},
 				{
-					Rbrace,
-					"}",
+					TokenType: Rbrace,
+					Literal:   "}",
 				},
 				{
-					Lbracket,
-					"[",
+					TokenType: Lbracket,
+					Literal:   "[",
 				},
 				{
-					Rbracket,
-					"]",
+					TokenType: Rbracket,
+					Literal:   "]",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -224,84 +225,84 @@ func TestLexicalize(t *testing.T) {
 			`&~^|:?.\-><<>>++--||&&==!=>=<=`,
 			[]*Token{
 				{
-					Ampersand,
-					"&",
+					TokenType: Ampersand,
+					Literal:   "&",
 				},
 				{
-					Tilde,
-					"~",
+					TokenType: Tilde,
+					Literal:   "~",
 				},
 				{
-					Caret,
-					"^",
+					TokenType: Caret,
+					Literal:   "^",
 				},
 				{
-					Vertical,
-					"|",
+					TokenType: Vertical,
+					Literal:   "|",
 				},
 				{
-					Colon,
-					":",
+					TokenType: Colon,
+					Literal:   ":",
 				},
 				{
-					Question,
-					"?",
+					TokenType: Question,
+					Literal:   "?",
 				},
 				{
-					Period,
-					".",
+					TokenType: Period,
+					Literal:   ".",
 				},
 				{
-					Backslash,
-					"\\",
+					TokenType: Backslash,
+					Literal:   "\\",
 				},
 				{
-					Arrow,
-					"->",
+					TokenType: Arrow,
+					Literal:   "->",
 				},
 				{
-					LeftShift,
-					`<<`,
+					TokenType: LeftShift,
+					Literal:   `<<`,
 				},
 				{
-					RightShift,
-					`>>`,
+					TokenType: RightShift,
+					Literal:   `>>`,
 				},
 				{
-					Increment,
-					`++`,
+					TokenType: Increment,
+					Literal:   `++`,
 				},
 				{
-					Decrement,
-					`--`,
+					TokenType: Decrement,
+					Literal:   `--`,
 				},
 				{
-					Or,
-					`||`,
+					TokenType: Or,
+					Literal:   `||`,
 				},
 				{
-					And,
-					`&&`,
+					TokenType: And,
+					Literal:   `&&`,
 				},
 				{
-					Eq,
-					`==`,
+					TokenType: Eq,
+					Literal:   `==`,
 				},
 				{
-					Ne,
-					`!=`,
+					TokenType: Ne,
+					Literal:   `!=`,
 				},
 				{
-					Gteq,
-					`>=`,
+					TokenType: Gteq,
+					Literal:   `>=`,
 				},
 				{
-					Lteq,
-					`<=`,
+					TokenType: Lteq,
+					Literal:   `<=`,
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -310,52 +311,52 @@ func TestLexicalize(t *testing.T) {
 			`+= -= *= /= |= &= <<= >>= ~= ^= %=`,
 			[]*Token{
 				{
-					PlusAssigne,
-					"+=",
+					TokenType: PlusAssigne,
+					Literal:   "+=",
 				},
 				{
-					MinusAssigne,
-					"-=",
+					TokenType: MinusAssigne,
+					Literal:   "-=",
 				},
 				{
-					AsteriskAssigne,
-					"*=",
+					TokenType: AsteriskAssigne,
+					Literal:   "*=",
 				},
 				{
-					SlashAssigne,
-					"/=",
+					TokenType: SlashAssigne,
+					Literal:   "/=",
 				},
 				{
-					VerticalAssigne,
-					"|=",
+					TokenType: VerticalAssigne,
+					Literal:   "|=",
 				},
 				{
-					AmpersandAssigne,
-					"&=",
+					TokenType: AmpersandAssigne,
+					Literal:   "&=",
 				},
 				{
-					LeftShiftAssigne,
-					"<<=",
+					TokenType: LeftShiftAssigne,
+					Literal:   "<<=",
 				},
 				{
-					RightShiftAssigne,
-					">>=",
+					TokenType: RightShiftAssigne,
+					Literal:   ">>=",
 				},
 				{
-					TildeAssigne,
-					"~=",
+					TokenType: TildeAssigne,
+					Literal:   "~=",
 				},
 				{
-					CaretAssigne,
-					"^=",
+					TokenType: CaretAssigne,
+					Literal:   "^=",
 				},
 				{
-					PercentAssigne,
-					"%=",
+					TokenType: PercentAssigne,
+					Literal:   "%=",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -364,24 +365,24 @@ func TestLexicalize(t *testing.T) {
 			`   ident00+123;   `,
 			[]*Token{
 				{
-					Word,
-					"ident00",
+					TokenType: Word,
+					Literal:   "ident00",
 				},
 				{
-					Plus,
-					"+",
+					TokenType: Plus,
+					Literal:   "+",
 				},
 				{
-					Integer,
-					"123",
+					TokenType: Integer,
+					Literal:   "123",
 				},
 				{
-					Semicolon,
-					";",
+					TokenType: Semicolon,
+					Literal:   ";",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -390,12 +391,12 @@ func TestLexicalize(t *testing.T) {
 			`# 1 "hoge.c"`,
 			[]*Token{
 				{
-					Comment,
-					" 1 \"hoge.c\"",
+					TokenType: Comment,
+					Literal:   " 1 \"hoge.c\"",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -405,16 +406,16 @@ func TestLexicalize(t *testing.T) {
 # 1 "<built-in>" 1`,
 			[]*Token{
 				{
-					Comment,
-					" 1 \"hoge.c\"",
+					TokenType: Comment,
+					Literal:   " 1 \"hoge.c\"",
 				},
 				{
-					Comment,
-					" 1 \"<built-in>\" 1",
+					TokenType: Comment,
+					Literal:   " 1 \"<built-in>\" 1",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -423,56 +424,56 @@ func TestLexicalize(t *testing.T) {
 			`0 0U 123 0xA1c 0765 0b0110 567u 567U 567l 567L 567lu 567UL`,
 			[]*Token{
 				{
-					Integer,
-					"0",
+					TokenType: Integer,
+					Literal:   "0",
 				},
 				{
-					Integer,
-					"0U",
+					TokenType: Integer,
+					Literal:   "0U",
 				},
 				{
-					Integer,
-					"123",
+					TokenType: Integer,
+					Literal:   "123",
 				},
 				{
-					Integer,
-					"0xA1c",
+					TokenType: Integer,
+					Literal:   "0xA1c",
 				},
 				{
-					Integer,
-					"0765",
+					TokenType: Integer,
+					Literal:   "0765",
 				},
 				{
-					Integer,
-					"0b0110",
+					TokenType: Integer,
+					Literal:   "0b0110",
 				},
 				{
-					Integer,
-					"567u",
+					TokenType: Integer,
+					Literal:   "567u",
 				},
 				{
-					Integer,
-					"567U",
+					TokenType: Integer,
+					Literal:   "567U",
 				},
 				{
-					Integer,
-					"567l",
+					TokenType: Integer,
+					Literal:   "567l",
 				},
 				{
-					Integer,
-					"567L",
+					TokenType: Integer,
+					Literal:   "567L",
 				},
 				{
-					Integer,
-					"567lu",
+					TokenType: Integer,
+					Literal:   "567lu",
 				},
 				{
-					Integer,
-					"567UL",
+					TokenType: Integer,
+					Literal:   "567UL",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -481,20 +482,20 @@ func TestLexicalize(t *testing.T) {
 			`0.123 987.123 123.`,
 			[]*Token{
 				{
-					Float,
-					"0.123",
+					TokenType: Float,
+					Literal:   "0.123",
 				},
 				{
-					Float,
-					"987.123",
+					TokenType: Float,
+					Literal:   "987.123",
 				},
 				{
-					Float,
-					"123.",
+					TokenType: Float,
+					Literal:   "123.",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -504,92 +505,92 @@ func TestLexicalize(t *testing.T) {
  extern volatile const typedef union struct enum __attribute__ void`,
 			[]*Token{
 				{
-					KeyReturn,
-					"return",
+					TokenType: KeyReturn,
+					Literal:   "return",
 				},
 				{
-					KeyIf,
-					"if",
+					TokenType: KeyIf,
+					Literal:   "if",
 				},
 				{
-					KeyElse,
-					"else",
+					TokenType: KeyElse,
+					Literal:   "else",
 				},
 				{
-					KeyWhile,
-					"while",
+					TokenType: KeyWhile,
+					Literal:   "while",
 				},
 				{
-					KeyDo,
-					"do",
+					TokenType: KeyDo,
+					Literal:   "do",
 				},
 				{
-					KeyGoto,
-					"goto",
+					TokenType: KeyGoto,
+					Literal:   "goto",
 				},
 				{
-					KeyFor,
-					"for",
+					TokenType: KeyFor,
+					Literal:   "for",
 				},
 				{
-					KeyBreak,
-					"break",
+					TokenType: KeyBreak,
+					Literal:   "break",
 				},
 				{
-					KeyContinue,
-					"continue",
+					TokenType: KeyContinue,
+					Literal:   "continue",
 				},
 				{
-					KeySwitch,
-					"switch",
+					TokenType: KeySwitch,
+					Literal:   "switch",
 				},
 				{
-					KeyCase,
-					"case",
+					TokenType: KeyCase,
+					Literal:   "case",
 				},
 				{
-					KeyDefault,
-					"default",
+					TokenType: KeyDefault,
+					Literal:   "default",
 				},
 				{
-					KeyExtern,
-					"extern",
+					TokenType: KeyExtern,
+					Literal:   "extern",
 				},
 				{
-					KeyVolatile,
-					"volatile",
+					TokenType: KeyVolatile,
+					Literal:   "volatile",
 				},
 				{
-					KeyConst,
-					"const",
+					TokenType: KeyConst,
+					Literal:   "const",
 				},
 				{
-					KeyTypedef,
-					"typedef",
+					TokenType: KeyTypedef,
+					Literal:   "typedef",
 				},
 				{
-					KeyUnion,
-					"union",
+					TokenType: KeyUnion,
+					Literal:   "union",
 				},
 				{
-					KeyStruct,
-					"struct",
+					TokenType: KeyStruct,
+					Literal:   "struct",
 				},
 				{
-					KeyEnum,
-					"enum",
+					TokenType: KeyEnum,
+					Literal:   "enum",
 				},
 				{
-					KeyAttribute,
-					"__attribute__",
+					TokenType: KeyAttribute,
+					Literal:   "__attribute__",
 				},
 				{
-					KeyVoid,
-					"void",
+					TokenType: KeyVoid,
+					Literal:   "void",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -598,36 +599,36 @@ func TestLexicalize(t *testing.T) {
 			`char hoge[] = "hello";`,
 			[]*Token{
 				{
-					Word,
-					"char",
+					TokenType: Word,
+					Literal:   "char",
 				},
 				{
-					Word,
-					"hoge",
+					TokenType: Word,
+					Literal:   "hoge",
 				},
 				{
-					Lbracket,
-					"[",
+					TokenType: Lbracket,
+					Literal:   "[",
 				},
 				{
-					Rbracket,
-					"]",
+					TokenType: Rbracket,
+					Literal:   "]",
 				},
 				{
-					Assign,
-					"=",
+					TokenType: Assign,
+					Literal:   "=",
 				},
 				{
-					Str,
-					"\"hello\"",
+					TokenType: Str,
+					Literal:   "\"hello\"",
 				},
 				{
-					Semicolon,
-					";",
+					TokenType: Semicolon,
+					Literal:   ";",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -636,28 +637,28 @@ func TestLexicalize(t *testing.T) {
 			`int hoge = 0;`,
 			[]*Token{
 				{
-					Word,
-					"int",
+					TokenType: Word,
+					Literal:   "int",
 				},
 				{
-					Word,
-					"hoge",
+					TokenType: Word,
+					Literal:   "hoge",
 				},
 				{
-					Assign,
-					"=",
+					TokenType: Assign,
+					Literal:   "=",
 				},
 				{
-					Integer,
-					"0",
+					TokenType: Integer,
+					Literal:   "0",
 				},
 				{
-					Semicolon,
-					";",
+					TokenType: Semicolon,
+					Literal:   ";",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -674,88 +675,88 @@ int func(int a) {
 `,
 			[]*Token{
 				{
-					Comment,
-					` 1 "hoge.c"`,
+					TokenType: Comment,
+					Literal:   ` 1 "hoge.c"`,
 				},
 				{
-					Word,
-					`int`,
+					TokenType: Word,
+					Literal:   `int`,
 				},
 				{
-					Word,
-					`func`,
+					TokenType: Word,
+					Literal:   `func`,
 				},
 				{
-					Lparen,
-					`(`,
+					TokenType: Lparen,
+					Literal:   `(`,
 				},
 				{
-					Word,
-					`int`,
+					TokenType: Word,
+					Literal:   `int`,
 				},
 				{
-					Word,
-					`a`,
+					TokenType: Word,
+					Literal:   `a`,
 				},
 				{
-					Rparen,
-					`)`,
+					TokenType: Rparen,
+					Literal:   `)`,
 				},
 				{
-					Lbrace,
-					`{`,
+					TokenType: Lbrace,
+					Literal:   `{`,
 				},
 				{
-					Word,
-					`a`,
+					TokenType: Word,
+					Literal:   `a`,
 				},
 				{
-					Assign,
-					`=`,
+					TokenType: Assign,
+					Literal:   `=`,
 				},
 				{
-					Word,
-					`a`,
+					TokenType: Word,
+					Literal:   `a`,
 				},
 				{
-					Plus,
-					`+`,
+					TokenType: Plus,
+					Literal:   `+`,
 				},
 				{
-					Lparen,
-					`(`,
+					TokenType: Lparen,
+					Literal:   `(`,
 				},
 				{
-					Integer,
-					`10`,
+					TokenType: Integer,
+					Literal:   `10`,
 				},
 				{
-					Rparen,
-					`)`,
+					TokenType: Rparen,
+					Literal:   `)`,
 				},
 				{
-					Semicolon,
-					`;`,
+					TokenType: Semicolon,
+					Literal:   `;`,
 				},
 				{
-					KeyReturn,
-					`return`,
+					TokenType: KeyReturn,
+					Literal:   `return`,
 				},
 				{
-					Word,
-					`a`,
+					TokenType: Word,
+					Literal:   `a`,
 				},
 				{
-					Semicolon,
-					`;`,
+					TokenType: Semicolon,
+					Literal:   `;`,
 				},
 				{
-					Rbrace,
-					`}`,
+					TokenType: Rbrace,
+					Literal:   `}`,
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -764,16 +765,16 @@ int func(int a) {
 			`'A' '\n'`,
 			[]*Token{
 				{
-					Letter,
-					"A",
+					TokenType: Letter,
+					Literal:   `'A'`,
 				},
 				{
-					Letter,
-					"\\n",
+					TokenType: Letter,
+					Literal:   `'\n'`,
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -784,48 +785,48 @@ int func(int a) {
 `,
 			[]*Token{
 				{
-					Letter,
-					`"`,
+					TokenType: Letter,
+					Literal:   `'"'`,
 				},
 				{
-					Letter,
-					`\\`,
+					TokenType: Letter,
+					Literal:   `'\\'`,
 				},
 				{
-					Letter,
-					`\b`,
+					TokenType: Letter,
+					Literal:   `'\b'`,
 				},
 				{
-					Letter,
-					`\f`,
+					TokenType: Letter,
+					Literal:   `'\f'`,
 				},
 				{
-					Letter,
-					`\n`,
+					TokenType: Letter,
+					Literal:   `'\n'`,
 				},
 				{
-					Letter,
-					`\r`,
+					TokenType: Letter,
+					Literal:   `'\r'`,
 				},
 				{
-					Letter,
-					`\t`,
+					TokenType: Letter,
+					Literal:   `'\t'`,
 				},
 				{
-					Letter,
-					`\033`,
+					TokenType: Letter,
+					Literal:   `'\033'`,
 				},
 				{
-					Letter,
-					`\'`,
+					TokenType: Letter,
+					Literal:   `'\''`,
 				},
 				{
-					Letter,
-					`\0`,
+					TokenType: Letter,
+					Literal:   `'\0'`,
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -841,32 +842,32 @@ int func(int a) {
             `,
 			[]*Token{
 				{
-					Str,
-					`"\\\\"`,
+					TokenType: Str,
+					Literal:   `"\\\\"`,
 				},
 				{
-					Str,
-					`"\\b"`,
+					TokenType: Str,
+					Literal:   `"\\b"`,
 				},
 				{
-					Str,
-					`"\\f"`,
+					TokenType: Str,
+					Literal:   `"\\f"`,
 				},
 				{
-					Str,
-					`"\\n"`,
+					TokenType: Str,
+					Literal:   `"\\n"`,
 				},
 				{
-					Str,
-					`"\\r"`,
+					TokenType: Str,
+					Literal:   `"\\r"`,
 				},
 				{
-					Str,
-					`"\\t"`,
+					TokenType: Str,
+					Literal:   `"\\t"`,
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -878,16 +879,16 @@ int func(int a) {
 `,
 			[]*Token{
 				{
-					Str,
-					`"\""`,
+					TokenType: Str,
+					Literal:   `"\""`,
 				},
 				{
-					Str,
-					`"\" ***\\"`,
+					TokenType: Str,
+					Literal:   `"\" ***\\"`,
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -898,12 +899,12 @@ int func(int a) {
 `,
 			[]*Token{
 				{
-					KeySizeof,
-					"sizeof",
+					TokenType: KeySizeof,
+					Literal:   "sizeof",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -912,12 +913,12 @@ int func(int a) {
 			`__asm`,
 			[]*Token{
 				{
-					KeyAsm,
-					"__asm",
+					TokenType: KeyAsm,
+					Literal:   "__asm",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -926,12 +927,12 @@ int func(int a) {
 			`static`,
 			[]*Token{
 				{
-					KeyStatic,
-					"static",
+					TokenType: KeyStatic,
+					Literal:   "static",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -970,12 +971,12 @@ func TestLexComment(t *testing.T) {
 			`/**/`,
 			[]*Token{
 				{
-					Comment,
-					"",
+					TokenType: Comment,
+					Literal:   "",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -984,12 +985,12 @@ func TestLexComment(t *testing.T) {
 			`/* hoge */`,
 			[]*Token{
 				{
-					Comment,
-					" hoge ",
+					TokenType: Comment,
+					Literal:   " hoge ",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -998,12 +999,12 @@ func TestLexComment(t *testing.T) {
 			`/** */`,
 			[]*Token{
 				{
-					Comment,
-					"* ",
+					TokenType: Comment,
+					Literal:   "* ",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -1012,12 +1013,12 @@ func TestLexComment(t *testing.T) {
 			`/* **/`,
 			[]*Token{
 				{
-					Comment,
-					" *",
+					TokenType: Comment,
+					Literal:   " *",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -1026,12 +1027,12 @@ func TestLexComment(t *testing.T) {
 			`/* /* */`,
 			[]*Token{
 				{
-					Comment,
-					" /* ",
+					TokenType: Comment,
+					Literal:   " /* ",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -1040,12 +1041,96 @@ func TestLexComment(t *testing.T) {
 			`/* * / */`,
 			[]*Token{
 				{
-					Comment,
-					" * / ",
+					TokenType: Comment,
+					Literal:   " * / ",
 				},
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
+				},
+			},
+		},
+		{
+			"test line comment 1",
+			"// hoge\nint",
+			[]*Token{
+				{
+					TokenType: Comment,
+					Literal:   " hoge",
+				},
+				{
+					TokenType: Word,
+					Literal:   "int",
+				},
+				{
+					TokenType: Eof,
+					Literal:   "eof",
+				},
+			},
+		},
+		{
+			"test line comment 2",
+			"a = 1; // 日本語のコメント\r\n",
+			[]*Token{
+				{
+					TokenType: Word,
+					Literal:   "a",
+				},
+				{
+					TokenType: Assign,
+					Literal:   "=",
+				},
+				{
+					TokenType: Integer,
+					Literal:   "1",
+				},
+				{
+					TokenType: Semicolon,
+					Literal:   ";",
+				},
+				{
+					TokenType: Comment,
+					Literal:   " 日本語のコメント",
+				},
+				{
+					TokenType: Eof,
+					Literal:   "eof",
+				},
+			},
+		},
+		{
+			"test line comment 3",
+			"// hoge \\\n fuga\n//",
+			[]*Token{
+				{
+					TokenType: Comment,
+					Literal:   " hoge \\\n fuga",
+				},
+				{
+					TokenType: Comment,
+					Literal:   "",
+				},
+				{
+					TokenType: Eof,
+					Literal:   "eof",
+				},
+			},
+		},
+		{
+			"test line comment 4",
+			"/* // */ // /* */",
+			[]*Token{
+				{
+					TokenType: Comment,
+					Literal:   " // ",
+				},
+				{
+					TokenType: Comment,
+					Literal:   " /* */",
+				},
+				{
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -1073,6 +1158,19 @@ func TestLexComment(t *testing.T) {
 	}
 }
 
+func TestCommentStyle(t *testing.T) {
+	got, err := Lexicalize("/* block */ // line")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got[0].IsBlockComment() || got[0].IsLineComment() {
+		t.Errorf("%q is not a block comment", got[0].Raw)
+	}
+	if !got[1].IsLineComment() || got[1].IsBlockComment() {
+		t.Errorf("%q is not a line comment", got[1].Raw)
+	}
+}
+
 func TestBin(t *testing.T) {
 	testTbl := []struct {
 		comment string
@@ -1107,8 +1205,8 @@ func TestBin(t *testing.T) {
 			},
 			[]*Token{
 				{
-					Eof,
-					"eof",
+					TokenType: Eof,
+					Literal:   "eof",
 				},
 			},
 		},
@@ -1118,8 +1216,8 @@ func TestBin(t *testing.T) {
 		t.Logf("%s", tt.comment)
 		l := NewLexer(string(tt.bin))
 		_, err := l.lexicalize()
-		if err == nil {
-			t.Fatal(err)
+		if !errors.Is(err, ErrBinary) {
+			t.Fatalf("got err=%v, expect %v", err, ErrBinary)
 		}
 	}
 }
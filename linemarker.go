@@ -0,0 +1,133 @@
+package clanglex
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LineFlag はラインマーカに付くフラグ
+type LineFlag int
+
+const (
+	FlagEnter        LineFlag = 1 << iota // 1: インクルードファイルの開始
+	FlagReturn                            // 2: インクルード元のファイルへの復帰
+	FlagSystemHeader                      // 3: システムヘッダ
+	FlagExternC                           // 4: extern "C" で囲まれている
+)
+
+// Origin はラインマーカ(# 42 "file.c" 2 や #line 42 "file.c")から求めた
+// プリプロセス前の元ファイルでの位置を表す
+// ラインマーカがなければ File は Lexer のファイル名, Line は物理行になる
+type Origin struct {
+	File  string
+	Line  int
+	Flags LineFlag // 直前のラインマーカのフラグ
+}
+
+// Has はフラグ f が立っているか返す
+func (o Origin) Has(f LineFlag) bool {
+	return o.Flags&f != 0
+}
+
+type linemarker struct {
+	file  string
+	line  int
+	flags LineFlag
+	// ラインマーカ自身の物理行
+	at int
+}
+
+// origin は物理行 line にあるトークンの元ファイルでの位置を返す
+func (l *Lexer) origin(line int) Origin {
+	m := l.marker
+	if m == nil {
+		return Origin{File: l.file, Line: line}
+	}
+	return Origin{File: m.file, Line: m.line + line - m.at - 1, Flags: m.flags}
+}
+
+// applyLinemarker は # 以降の文字列 s がラインマーカなら現在のラインマーカとして記録する
+// at は # のある物理行
+func (l *Lexer) applyLinemarker(s string, at int) bool {
+	m, ok := parseLinemarker(s)
+	if !ok {
+		return false
+	}
+	if m.file == "" {
+		// ファイル名の省略時は現在のファイル名を引き継ぐ
+		m.file = l.origin(at).File
+	}
+	m.at = at
+	l.marker = m
+	return true
+}
+
+// parseLinemarker は # の後ろの文字列を解析する
+// "42 "file" 1 3" と "line 42 "file"" の2つの形式を受け付ける
+func parseLinemarker(s string) (*linemarker, bool) {
+	s = strings.TrimLeft(s, " \t")
+	isLine := false
+	if strings.HasPrefix(s, "line") {
+		s = s[len("line"):]
+		if s == "" || (s[0] != ' ' && s[0] != '\t') {
+			return nil, false
+		}
+		s = strings.TrimLeft(s, " \t")
+		isLine = true
+	}
+
+	// 行番号
+	i := 0
+	for i < len(s) && isDec(s[i]) {
+		i++
+	}
+	if i == 0 {
+		return nil, false
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return nil, false
+	}
+	m := &linemarker{line: n}
+	s = strings.TrimLeft(s[i:], " \t\r")
+	if s == "" {
+		return m, true
+	}
+
+	// ファイル名
+	if s[0] != '"' {
+		return nil, false
+	}
+	var name []byte
+	for i = 1; i < len(s) && s[i] != '"'; i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		name = append(name, s[i])
+	}
+	if i >= len(s) {
+		return nil, false
+	}
+	m.file = string(name)
+	s = s[i+1:]
+
+	// フラグ(#line には付かない)
+	for _, f := range strings.Fields(s) {
+		if isLine {
+			return nil, false
+		}
+		switch f {
+		case "1":
+			m.flags |= FlagEnter
+		case "2":
+			m.flags |= FlagReturn
+		case "3":
+			m.flags |= FlagSystemHeader
+		case "4":
+			m.flags |= FlagExternC
+		default:
+			return nil, false
+		}
+	}
+	return m, true
+}
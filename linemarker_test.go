@@ -0,0 +1,105 @@
+package clanglex
+
+import (
+	"testing"
+)
+
+func TestLinemarker(t *testing.T) {
+	src := `# 1 "main.c"
+int a;
+# 1 "/usr/include/stdio.h" 1 3 4
+extern int printf;
+
+# 3 "main.c" 2
+int b;
+#line 100 "C:\\src\\can.c"
+int c;
+#line 200
+int d;
+`
+	testTbl := []struct {
+		literal string
+		origin  Origin
+	}{
+		{` 1 "main.c"`, Origin{"main.c", 1, 0}},
+		{"int", Origin{"main.c", 1, 0}},
+		{"a", Origin{"main.c", 1, 0}},
+		{";", Origin{"main.c", 1, 0}},
+		{` 1 "/usr/include/stdio.h" 1 3 4`, Origin{"/usr/include/stdio.h", 1, FlagEnter | FlagSystemHeader | FlagExternC}},
+		{"extern", Origin{"/usr/include/stdio.h", 1, FlagEnter | FlagSystemHeader | FlagExternC}},
+		{"int", Origin{"/usr/include/stdio.h", 1, FlagEnter | FlagSystemHeader | FlagExternC}},
+		{"printf", Origin{"/usr/include/stdio.h", 1, FlagEnter | FlagSystemHeader | FlagExternC}},
+		{";", Origin{"/usr/include/stdio.h", 1, FlagEnter | FlagSystemHeader | FlagExternC}},
+		{` 3 "main.c" 2`, Origin{"main.c", 3, FlagReturn}},
+		{"int", Origin{"main.c", 3, FlagReturn}},
+		{"b", Origin{"main.c", 3, FlagReturn}},
+		{";", Origin{"main.c", 3, FlagReturn}},
+		{`line 100 "C:\\src\\can.c"`, Origin{`C:\src\can.c`, 100, 0}},
+		{"int", Origin{`C:\src\can.c`, 100, 0}},
+		{"c", Origin{`C:\src\can.c`, 100, 0}},
+		{";", Origin{`C:\src\can.c`, 100, 0}},
+		{`line 200`, Origin{`C:\src\can.c`, 200, 0}},
+		{"int", Origin{`C:\src\can.c`, 200, 0}},
+		{"d", Origin{`C:\src\can.c`, 200, 0}},
+		{";", Origin{`C:\src\can.c`, 200, 0}},
+		{"eof", Origin{`C:\src\can.c`, 201, 0}},
+	}
+
+	got, err := Lexicalize(src, WithFileName("main.i"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(testTbl) {
+		t.Fatalf("got len=%v, expect len=%v", len(got), len(testTbl))
+	}
+	for i, v := range got {
+		e := testTbl[i]
+		if v.Literal != e.literal {
+			t.Errorf("got literal=%v, expect literal=%v", v.Literal, e.literal)
+		}
+		if v.Origin != e.origin {
+			t.Errorf("%q: got origin=%+v, expect origin=%+v", v.Literal, v.Origin, e.origin)
+		}
+	}
+}
+
+func TestNoLinemarker(t *testing.T) {
+	got, err := Lexicalize("a\n# pragma once\nb", WithFileName("hoge.h"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Origin{{"hoge.h", 1, 0}, {"hoge.h", 2, 0}, {"hoge.h", 3, 0}, {"hoge.h", 3, 0}}
+	if len(got) != len(expect) {
+		t.Fatalf("got len=%v, expect len=%v", len(got), len(expect))
+	}
+	for i, v := range got {
+		if v.Origin != expect[i] {
+			t.Errorf("%q: got origin=%+v, expect origin=%+v", v.Literal, v.Origin, expect[i])
+		}
+	}
+}
+
+func TestLinemarkerContinued(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  Origin
+	}{
+		{"test line", "#line 10 \"x.c\"\ny", Origin{"x.c", 10, 0}},
+		{"test continued line", "#line 10 \\\n\"x.c\"\ny", Origin{"x.c", 10, 0}},
+		{"test continued marker", "# 10 \\\n\"x.c\"\ny", Origin{"x.c", 10, 0}},
+		{"test continued twice", "#\\\nline \\\n10 \\\r\n\"x.c\"\n\ny", Origin{"x.c", 11, 0}},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src, WithFileName("main.i"), WithDirectives())
+		if err != nil {
+			t.Fatal(err)
+		}
+		y := got[len(got)-2]
+		if y.Literal != "y" || y.Origin != tt.expect {
+			t.Errorf("%q: got origin=%+v, expect origin=%+v", y.Literal, y.Origin, tt.expect)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package clanglex
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// encodingPrefix は現在位置が文字列, 文字リテラルの接頭辞 (L, u, U, u8) ならその長さを返す
+func (l *Lexer) encodingPrefix() int {
+	n := 0
+	switch {
+	case l.pos+2 < len(l.input) && l.input[l.pos:l.pos+2] == "u8":
+		n = 2
+	case l.pos+1 < len(l.input) && (l.input[l.pos] == 'L' || l.input[l.pos] == 'u' || l.input[l.pos] == 'U'):
+		n = 1
+	default:
+		return 0
+	}
+	if c := l.input[l.pos+n]; c != '"' && c != '\'' {
+		return 0
+	}
+	return n
+}
+
+// escape は s[i] の \ から始まるエスケープシーケンスの値と長さを返す
+// ucn は値が \u, \U による符号位置か(それ以外は符号単位の値)を表す
+func escape(s string, i int) (v uint32, n int, ucn bool, err error) {
+	if i+1 >= len(s) {
+		return 0, 0, false, fmt.Errorf("incomplete escape sequence")
+	}
+	c := s[i+1]
+	switch c {
+	case '\'', '"', '?', '\\':
+		return uint32(c), 2, false, nil
+	case 'a':
+		return '\a', 2, false, nil
+	case 'b':
+		return '\b', 2, false, nil
+	case 'f':
+		return '\f', 2, false, nil
+	case 'n':
+		return '\n', 2, false, nil
+	case 'r':
+		return '\r', 2, false, nil
+	case 't':
+		return '\t', 2, false, nil
+	case 'v':
+		return '\v', 2, false, nil
+	case 'e', 'E':
+		// GNU 拡張の ESC
+		return 0x1b, 2, false, nil
+	case 'x':
+		j := i + 2
+		for ; j < len(s) && isHex(s[j]); j++ {
+			if v > 0x0FFFFFFF {
+				return 0, 0, false, fmt.Errorf("hex escape sequence out of range")
+			}
+			v = v<<4 | uint32(hexValue(s[j]))
+		}
+		if j == i+2 {
+			return 0, 0, false, fmt.Errorf("\\x used with no following hex digits")
+		}
+		return v, j - i, false, nil
+	case 'u', 'U':
+		digits := 4
+		if c == 'U' {
+			digits = 8
+		}
+		j := i + 2
+		for ; j < len(s) && j < i+2+digits && isHex(s[j]); j++ {
+			v = v<<4 | uint32(hexValue(s[j]))
+		}
+		if j != i+2+digits {
+			return 0, 0, false, fmt.Errorf("incomplete universal character name %s", s[i:j])
+		}
+		if v > unicode.MaxRune || 0xD800 <= v && v <= 0xDFFF {
+			return 0, 0, false, fmt.Errorf("invalid universal character %s", s[i:j])
+		}
+		return v, j - i, true, nil
+	}
+	if '0' <= c && c <= '7' {
+		j := i + 1
+		for ; j < len(s) && j < i+4 && '0' <= s[j] && s[j] <= '7'; j++ {
+			v = v<<3 | uint32(s[j]-'0')
+		}
+		return v, j - i, false, nil
+	}
+	return 0, 0, false, fmt.Errorf("unknown escape sequence '\\%c'", c)
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	}
+	return c - 'A' + 10
+}
+
+// 行の継続(\ と改行)は字句解析より前に取り除かれる
+var lineSplices = strings.NewReplacer("\\\r\n", "", "\\\n", "")
+
+// charUnit は文字, 文字列リテラルの中身の1文字
+type charUnit struct {
+	v    uint32
+	code bool // v が符号位置(通常の文字と \u, \U)なら true, 符号単位(\x と8進)なら false
+	mb   bool // UTF-8 以外の文字コードのマルチバイト文字のバイト
+}
+
+// splitChars は引用符の中身 s を文字ごとに分ける
+// UTF-8 以外の文字コードのマルチバイト文字は符号単位(バイト)の並びにする
+func splitChars(s string, enc Encoding) ([]charUnit, error) {
+	s = lineSplices.Replace(s)
+	us := []charUnit{}
+	for i := 0; i < len(s); {
+		if n := enc.charLen(s, i); n > 1 {
+			for _, b := range []byte(s[i : i+n]) {
+				us = append(us, charUnit{v: uint32(b), mb: true})
+			}
+			i += n
+			continue
+		}
+		if s[i] == '\\' {
+			v, n, ucn, err := escape(s, i)
+			if err != nil {
+				return nil, err
+			}
+			us = append(us, charUnit{v: v, code: ucn})
+			i += n
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && n == 1 {
+			// UTF-8 でないバイトはそのまま符号単位にする
+			us = append(us, charUnit{v: uint32(s[i])})
+		} else {
+			us = append(us, charUnit{v: uint32(r), code: true})
+		}
+		i += n
+	}
+	return us, nil
+}
+
+// checkChar は引用符 quote と next の間の文字リテラルの中身を検査する
+func (l *Lexer) checkChar(quote int, next int) error {
+	if next == quote+1 {
+		return l.errorf(ErrEmptyChar, l.pos, "empty character constant")
+	}
+	if _, err := splitChars(l.input[quote+1:next], l.enc); err != nil {
+		return l.errorf(ErrBadEscape, l.pos, "%v", err)
+	}
+	return nil
+}
+
+// CharValue は文字リテラルの値をデータモデル m で求める
+// 接頭辞のない文字リテラルは int の値 (複数文字なら GCC と同じく各バイトを並べた値),
+// 接頭辞のある文字リテラルは符号位置(\x と8進のエスケープは符号単位)を返す
+// m が nil なら LP64 とする
+func (t *Token) CharValue(m *DataModel) (int64, error) {
+	if t.TokenType != Letter || len(t.Literal) < 2 {
+		return 0, fmt.Errorf("clanglex: %v is not a character constant", t.TokenType)
+	}
+	if m == nil {
+		m = LP64
+	}
+	us, err := splitChars(t.Literal[1:len(t.Literal)-1], t.enc)
+	if err != nil {
+		return 0, &LexError{Kind: ErrBadEscape, Pos: t.Pos, Msg: err.Error()}
+	}
+	if len(us) == 0 {
+		return 0, &LexError{Kind: ErrEmptyChar, Pos: t.Pos, Msg: "empty character constant"}
+	}
+
+	if err := t.checkEncoding(us); err != nil {
+		return 0, err
+	}
+	bits := elemBits(t.Prefix, m)
+	units, ok := encodeUnits(us, t.Prefix, bits)
+	if !ok {
+		return 0, &LexError{Kind: ErrOutOfRange, Pos: t.Pos, Msg: fmt.Sprintf("escape sequence out of range in %s", t.Raw)}
+	}
+	if t.Prefix != "" {
+		if len(units) != 1 {
+			return 0, &LexError{Kind: ErrOutOfRange, Pos: t.Pos, Msg: fmt.Sprintf("character too large for enclosing character literal type in %s", t.Raw)}
+		}
+		return int64(units[0]), nil
+	}
+	if len(units) == 1 {
+		// char の値を int に変換する
+		if m.UnsignedChar {
+			return int64(units[0]), nil
+		}
+		return signExtend(uint64(units[0]), bits), nil
+	}
+	// 複数文字は int に収まる分だけ並べる
+	var v uint64
+	for _, u := range units {
+		v = v<<uint(bits) | uint64(u)
+	}
+	return signExtend(v, m.IntBits), nil
+}
+
+// elemBits は接頭辞 prefix の文字, 文字列リテラルの要素型のビット数を返す
+func elemBits(prefix string, m *DataModel) int {
+	switch prefix {
+	case "u":
+		return 16
+	case "U":
+		return 32
+	case "L":
+		return m.WcharBits
+	}
+	return m.CharBits
+}
+
+// encodeUnits は文字を要素型の符号単位に変換する
+// 符号位置は接頭辞がないか u8 なら UTF-8, 要素型が 16 ビットなら UTF-16 にする
+// \x と8進のエスケープの値が要素型に収まらなければ ok が false になる
+func encodeUnits(us []charUnit, prefix string, bits int) (units []uint32, ok bool) {
+	for _, u := range us {
+		switch {
+		case !u.code:
+			if uint64(u.v) >= 1<<uint(bits) {
+				return nil, false
+			}
+			units = append(units, u.v)
+		case prefix == "" || prefix == "u8":
+			for _, b := range []byte(string(rune(u.v))) {
+				units = append(units, uint32(b))
+			}
+		case bits == 16:
+			for _, c := range utf16.Encode([]rune{rune(u.v)}) {
+				units = append(units, uint32(c))
+			}
+		default:
+			units = append(units, u.v)
+		}
+	}
+	return units, true
+}
+
+// checkEncoding は接頭辞付きのリテラルに UTF-8 以外の文字コードのマルチバイト文字がないか調べる
+// 文字コードの変換表を持たないので, 接頭辞がないときだけバイト列のまま扱える
+func (t *Token) checkEncoding(us []charUnit) error {
+	if t.Prefix == "" {
+		return nil
+	}
+	for _, u := range us {
+		if u.mb {
+			return &LexError{Kind: ErrEncoding, Pos: t.Pos, Msg: fmt.Sprintf("cannot convert %v character in %s", t.enc, t.Raw)}
+		}
+	}
+	return nil
+}
+
+// signExtend は v の下位 bits ビットを符号付き整数として返す
+func signExtend(v uint64, bits int) int64 {
+	shift := uint(64 - bits)
+	return int64(v<<shift) >> shift
+}
+
+// stringChars は文字列リテラル(ConcatStrings でまとめたものを含む)の中身を文字ごとに分ける
+func (t *Token) stringChars() ([]charUnit, error) {
+	if t.TokenType != Str {
+		return nil, fmt.Errorf("clanglex: %v is not a string literal", t.TokenType)
+	}
+	pieces := t.Pieces
+	if len(pieces) == 0 {
+		pieces = []*Token{t}
+	}
+	us := []charUnit{}
+	for _, p := range pieces {
+		if len(p.Literal) < 2 {
+			return nil, fmt.Errorf("clanglex: malformed string literal %s", p.Literal)
+		}
+		pus, err := splitChars(p.Literal[1:len(p.Literal)-1], p.enc)
+		if err != nil {
+			return nil, &LexError{Kind: ErrBadEscape, Pos: p.Pos, Msg: err.Error()}
+		}
+		us = append(us, pus...)
+	}
+	return us, nil
+}
+
+// StringValue は文字列リテラルの中身を Go の文字列で返す(終端の 0 を含まない)
+// 接頭辞がないか u8 なら C と同じバイト列, それ以外は符号位置を UTF-8 にした文字列になる
+// \x と8進のエスケープは, 接頭辞がないか u8 ならそのバイト, それ以外はその値の符号位置とする
+func (t *Token) StringValue() (string, error) {
+	us, err := t.stringChars()
+	if err != nil {
+		return "", err
+	}
+	if err := t.checkEncoding(us); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, u := range us {
+		if !u.code && (t.Prefix == "" || t.Prefix == "u8") {
+			b.WriteByte(byte(u.v))
+		} else {
+			b.WriteRune(rune(u.v))
+		}
+	}
+	return b.String(), nil
+}
+
+// CodeUnits は文字列リテラルをデータモデル m での要素型の値の並びで返す(終端の 0 を含まない)
+// 要素型は接頭辞で決まり, 接頭辞がないか u8 なら UTF-8, u と 16 ビットの L なら UTF-16 になる
+// m が nil なら LP64 とする
+func (t *Token) CodeUnits(m *DataModel) ([]uint32, error) {
+	us, err := t.stringChars()
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = LP64
+	}
+	if err := t.checkEncoding(us); err != nil {
+		return nil, err
+	}
+	units, ok := encodeUnits(us, t.Prefix, elemBits(t.Prefix, m))
+	if !ok {
+		return nil, &LexError{Kind: ErrOutOfRange, Pos: t.Pos, Msg: fmt.Sprintf("escape sequence out of range in %s", t.Raw)}
+	}
+	return units, nil
+}
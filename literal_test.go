@@ -0,0 +1,323 @@
+package clanglex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncodingPrefix(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  []*Token
+	}{
+		{
+			"test string prefix",
+			`L"wide" u"utf16" U"utf32" u8"utf8" "plain"`,
+			[]*Token{
+				{TokenType: Str, Literal: `"wide"`, Raw: `L"wide"`, Prefix: "L"},
+				{TokenType: Str, Literal: `"utf16"`, Raw: `u"utf16"`, Prefix: "u"},
+				{TokenType: Str, Literal: `"utf32"`, Raw: `U"utf32"`, Prefix: "U"},
+				{TokenType: Str, Literal: `"utf8"`, Raw: `u8"utf8"`, Prefix: "u8"},
+				{TokenType: Str, Literal: `"plain"`, Raw: `"plain"`, Prefix: ""},
+			},
+		},
+		{
+			"test char prefix",
+			`L'a' u'b' U'\n' u8'c' 'd'`,
+			[]*Token{
+				{TokenType: Letter, Literal: `'a'`, Raw: `L'a'`, Prefix: "L"},
+				{TokenType: Letter, Literal: `'b'`, Raw: `u'b'`, Prefix: "u"},
+				{TokenType: Letter, Literal: `'\n'`, Raw: `U'\n'`, Prefix: "U"},
+				{TokenType: Letter, Literal: `'c'`, Raw: `u8'c'`, Prefix: "u8"},
+				{TokenType: Letter, Literal: `'d'`, Raw: `'d'`, Prefix: ""},
+			},
+		},
+		{
+			"test identifiers",
+			`L u u8 U8"x" l"y" Lx uL"z"`,
+			[]*Token{
+				{TokenType: Word, Literal: `L`, Raw: `L`},
+				{TokenType: Word, Literal: `u`, Raw: `u`},
+				{TokenType: Word, Literal: `u8`, Raw: `u8`},
+				{TokenType: Word, Literal: `U8`, Raw: `U8`},
+				{TokenType: Str, Literal: `"x"`, Raw: `"x"`},
+				{TokenType: Word, Literal: `l`, Raw: `l`},
+				{TokenType: Str, Literal: `"y"`, Raw: `"y"`},
+				{TokenType: Word, Literal: `Lx`, Raw: `Lx`},
+				{TokenType: Word, Literal: `uL`, Raw: `uL`},
+				{TokenType: Str, Literal: `"z"`, Raw: `"z"`},
+			},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect)+1 {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect)+1)
+		}
+		for i, e := range tt.expect {
+			g := got[i]
+			if g.TokenType != e.TokenType || g.Literal != e.Literal || g.Raw != e.Raw || g.Prefix != e.Prefix {
+				t.Errorf("got=%v %q %q %q, expect=%v %q %q %q", g.TokenType, g.Literal, g.Raw, g.Prefix, e.TokenType, e.Literal, e.Raw, e.Prefix)
+			}
+		}
+	}
+}
+
+func TestEncodingPrefixError(t *testing.T) {
+	_, err := Lexicalize("a = L\"abc\n")
+	if err == nil || err.Error() != `1:5: missing terminating '"' character` {
+		t.Errorf("got=%v", err)
+	}
+
+	// エラー回復モードでは接頭辞を含めて Illegal になる
+	got, _ := Lexicalize("a = u8'x\nb", WithRecovery())
+	expect := []*Token{
+		{TokenType: Word, Literal: "a"},
+		{TokenType: Assign, Literal: "="},
+		{TokenType: Illegal, Literal: "u8'x"},
+		{TokenType: Word, Literal: "b"},
+	}
+	for i, e := range expect {
+		if got[i].TokenType != e.TokenType || got[i].Literal != e.Literal {
+			t.Errorf("got=%v %q, expect=%v %q", got[i].TokenType, got[i].Literal, e.TokenType, e.Literal)
+		}
+	}
+}
+
+func TestCharValue(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		model   *DataModel
+		expect  int64
+	}{
+		{"test plain", `'A'`, nil, 65},
+		{"test simple escape", `'\n'`, nil, 10},
+		{"test quote escape", `'\''`, nil, 39},
+		{"test question escape", `'\?'`, nil, 63},
+		{"test hex", `'\x41'`, nil, 65},
+		{"test hex signed char", `'\xFF'`, nil, -1},
+		{"test hex unsigned char", `'\xFF'`, &DataModel{CharBits: 8, IntBits: 32, UnsignedChar: true}, 255},
+		{"test octal", `'\101'`, nil, 65},
+		{"test octal 3 digits", `'\0101'`, nil, 0x0831},
+		{"test octal zero", `'\0'`, nil, 0},
+		{"test octal signed", `'\377'`, nil, -1},
+		{"test gnu escape", `'\e'`, nil, 27},
+		{"test multi char", `'ABCD'`, nil, 0x41424344},
+		{"test multi char 16 bit int", `'AB'`, IP16, 0x4142},
+		{"test multi char overflow", `'ABCDE'`, nil, 0x42434445},
+		{"test utf-8 in plain", `'é'`, nil, 0xC3A9},
+		{"test ucn in plain", `'\u00E9'`, nil, 0xC3A9},
+		{"test line splice", "'\\\nA'", nil, 65},
+		{"test wide", `L'A'`, nil, 65},
+		{"test wide ucn", `L'\u3042'`, nil, 0x3042},
+		{"test wide utf-8", `L'あ'`, nil, 0x3042},
+		{"test wide big", `L'\U0001F600'`, nil, 0x1F600},
+		{"test char16", `u'あ'`, nil, 0x3042},
+		{"test char32", `U'\U0001F600'`, nil, 0x1F600},
+		{"test char16 hex", `u'\xFFFF'`, nil, 0xFFFF},
+		{"test utf-8 char", `u8'a'`, nil, 97},
+		{"test utf-8 char hex", `u8'\xFF'`, nil, 255},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		ts, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ts[0].Raw != tt.src {
+			t.Errorf("got raw=%q, expect=%q", ts[0].Raw, tt.src)
+		}
+		v, err := ts[0].CharValue(tt.model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != tt.expect {
+			t.Errorf("got=%#x, expect=%#x", v, tt.expect)
+		}
+	}
+}
+
+func TestCharError(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		kind    ErrorKind
+		msg     string
+	}{
+		{"test empty", `a = ''`, ErrEmptyChar, "1:5: empty character constant"},
+		{"test unknown escape", `'\q'`, ErrBadEscape, `1:1: unknown escape sequence '\q'`},
+		{"test hex without digits", `'\x'`, ErrBadEscape, `1:1: \x used with no following hex digits`},
+		{"test hex too large", `'\x123456789'`, ErrBadEscape, "1:1: hex escape sequence out of range"},
+		{"test incomplete ucn", `'\u12'`, ErrBadEscape, `1:1: incomplete universal character name \u12`},
+		{"test surrogate", `L'\uD800'`, ErrBadEscape, `1:1: invalid universal character \uD800`},
+		{"test too large ucn", `U'\U00110000'`, ErrBadEscape, `1:1: invalid universal character \U00110000`},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		_, err := Lexicalize(tt.src)
+		if !errors.Is(err, tt.kind) {
+			t.Fatalf("got=%v, expect=%v", err, tt.kind)
+		}
+		if err.Error() != tt.msg {
+			t.Errorf("got=%q, expect=%q", err.Error(), tt.msg)
+		}
+	}
+
+	valueTbl := []struct {
+		comment string
+		src     string
+		model   *DataModel
+	}{
+		{"test hex out of char", `'\x100'`, nil},
+		{"test octal out of char", `'\777'`, nil},
+		{"test char16 too large", `u'\U0001F600'`, nil},
+		{"test 16 bit wchar", `L'\U0001F600'`, LLP64},
+		{"test utf-8 char multi byte", `u8'é'`, nil},
+		{"test prefixed multi char", `L'ab'`, nil},
+	}
+
+	for _, tt := range valueTbl {
+		t.Logf("%s", tt.comment)
+		ts, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ts[0].CharValue(tt.model); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("got=%v, expect out of range", err)
+		}
+	}
+}
+
+func TestStringValue(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  string
+		units   []uint32
+	}{
+		{"test plain", `"abc"`, "abc", []uint32{'a', 'b', 'c'}},
+		{"test empty", `""`, "", nil},
+		{"test escapes", `"\"\\\?\a\b\f\n\r\t\v\'"`, "\"\\?\a\b\f\n\r\t\v'", []uint32{'"', '\\', '?', 7, 8, 12, 10, 13, 9, 11, '\''}},
+		{"test hex and octal", `"\x41\101\0\377"`, "AA\x00\xff", []uint32{0x41, 0x41, 0, 0xFF}},
+		{"test octal max 3 digits", `"\1234"`, "S4", []uint32{0x53, '4'}},
+		{"test ucn", `"\u3042"`, "あ", []uint32{0xE3, 0x81, 0x82}},
+		{"test utf-8 source", `"あ"`, "あ", []uint32{0xE3, 0x81, 0x82}},
+		{"test line splice", "\"ab\\\ncd\"", "abcd", []uint32{'a', 'b', 'c', 'd'}},
+		{"test u8", `u8"\u00E9"`, "é", []uint32{0xC3, 0xA9}},
+		{"test char16", `u"a\U0001F600"`, "a\U0001F600", []uint32{'a', 0xD83D, 0xDE00}},
+		{"test char32", `U"a\U0001F600"`, "a\U0001F600", []uint32{'a', 0x1F600}},
+		{"test wide", `L"\x3042あ"`, "ああ", []uint32{0x3042, 0x3042}},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		ts, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ts[0].StringValue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.expect {
+			t.Errorf("got=%q, expect=%q", got, tt.expect)
+		}
+		units, err := ts[0].CodeUnits(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(units) != len(tt.units) {
+			t.Fatalf("got=%x, expect=%x", units, tt.units)
+		}
+		for i := range units {
+			if units[i] != tt.units[i] {
+				t.Errorf("got=%x, expect=%x", units, tt.units)
+				break
+			}
+		}
+	}
+
+	// Windows の wchar_t は UTF-16
+	ts, _ := Lexicalize(`L"\U0001F600"`)
+	units, err := ts[0].CodeUnits(LLP64)
+	if err != nil || len(units) != 2 || units[0] != 0xD83D {
+		t.Errorf("got=%x, %v", units, err)
+	}
+
+	// 要素型に収まらないエスケープ
+	ts, _ = Lexicalize(`"\x100" u"\x10000"`)
+	for _, tk := range ts[:2] {
+		if _, err := tk.CodeUnits(nil); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("got=%v, expect out of range", err)
+		}
+	}
+
+	if _, err := Lexicalize(`"abc\q"`); !errors.Is(err, ErrBadEscape) || err.Error() != `1:1: unknown escape sequence '\q'` {
+		t.Errorf("got=%v, expect bad escape", err)
+	}
+}
+
+func TestConcatStrings(t *testing.T) {
+	ts, err := Lexicalize("s = \"abc\" /* c */ \"\\x12\"\n  L\"3\"; t = \"x\";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ConcatStrings(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []*Token{
+		{TokenType: Word, Literal: "s"},
+		{TokenType: Assign, Literal: "="},
+		{TokenType: Str, Literal: `"abc" "\x12" "3"`},
+		{TokenType: Comment, Literal: " c "},
+		{TokenType: Semicolon, Literal: ";"},
+		{TokenType: Word, Literal: "t"},
+		{TokenType: Assign, Literal: "="},
+		{TokenType: Str, Literal: `"x"`},
+		{TokenType: Semicolon, Literal: ";"},
+		{TokenType: Eof, Literal: "eof"},
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("got len=%v, expect len=%v", len(got), len(expect))
+	}
+	for i, e := range expect {
+		if got[i].TokenType != e.TokenType || got[i].Literal != e.Literal {
+			t.Errorf("got=%v %q, expect=%v %q", got[i].TokenType, got[i].Literal, e.TokenType, e.Literal)
+		}
+	}
+
+	s := got[2]
+	if s.Prefix != "L" || len(s.Pieces) != 3 || s.Raw != `"abc" "\x12" L"3"` {
+		t.Errorf("got prefix=%q pieces=%d raw=%q", s.Prefix, len(s.Pieces), s.Raw)
+	}
+	if s.Pos != ts[2].Pos || s.End != ts[5].End || s.End.Line != 2 {
+		t.Errorf("got pos=%v-%v", s.Pos, s.End)
+	}
+	if s.Pieces[1].Pos.Column != 19 || s.Pieces[2].Pos.Line != 2 {
+		t.Errorf("got pieces=%v %v", s.Pieces[1].Pos, s.Pieces[2].Pos)
+	}
+	// エスケープは元のトークンごとに解釈する("\x12" "3" は "\x123" ではない)
+	v, err := s.StringValue()
+	if err != nil || v != "abc\x123" {
+		t.Errorf("got=%q, %v", v, err)
+	}
+	units, err := s.CodeUnits(nil)
+	if err != nil || len(units) != 5 || units[3] != 0x12 || units[4] != '3' {
+		t.Errorf("got=%x, %v", units, err)
+	}
+
+	ts, _ = Lexicalize(`L"a" u8"b"`)
+	if _, err := ConcatStrings(ts); !errors.Is(err, ErrPrefixMismatch) {
+		t.Errorf("got=%v, expect prefix mismatch", err)
+	}
+}
@@ -0,0 +1,108 @@
+package clanglex
+
+// MSVC は規格 base に MSVC のキーワード, 整数の接尾辞(i64 など)と __asm ブロックを加えた Dialect を返す
+func MSVC(base *Dialect) *Dialect {
+	return NewDialect("msvc-"+base.Name, base, msvcKeywords, FeatureMSIntSuffix|FeatureMSAsmBlock)
+}
+
+// MSVC のキーワード
+var msvcKeywords = map[string]TokenType{
+	"__declspec":    KeyDeclspec,
+	"__int8":        KeyInt8,
+	"__int16":       KeyInt16,
+	"__int32":       KeyInt32,
+	"__int64":       KeyInt64,
+	"__cdecl":       KeyCdecl,
+	"_cdecl":        KeyCdecl,
+	"__stdcall":     KeyStdcall,
+	"_stdcall":      KeyStdcall,
+	"__fastcall":    KeyFastcall,
+	"_fastcall":     KeyFastcall,
+	"__thiscall":    KeyThiscall,
+	"__vectorcall":  KeyVectorcall,
+	"__forceinline": KeyForceinline,
+	"__inline":      KeyInline,
+	"__restrict":    KeyRestrict,
+	"__pragma":      KeyPragma,
+	"__asm":         KeyAsm,
+	"_asm":          KeyAsm,
+}
+
+// isMSIntSuffix は s が MSVC の整数の接尾辞(i8, i16, i32, i64 と ui64 など)か返す
+func isMSIntSuffix(s string) bool {
+	if len(s) > 0 && (s[0] == 'u' || s[0] == 'U') {
+		s = s[1:]
+	}
+	if len(s) < 2 || (s[0] != 'i' && s[0] != 'I') {
+		return false
+	}
+	switch s[1:] {
+	case "8", "16", "32", "64":
+		return true
+	}
+	return false
+}
+
+// readAsmBlock は __asm { ... } の { から } までを読む
+// アセンブラの ; から行末までのコメントと C のコメントの中の } は無視する
+func (l *Lexer) readAsmBlock() (*Token, error) {
+	start := l.pos
+	depth := 0
+	for next := l.pos; next < len(l.input); next++ {
+		c := l.input[next]
+		switch {
+		case c == ';' || (c == '/' && next+1 < len(l.input) && l.input[next+1] == '/'):
+			for next < len(l.input) && l.input[next] != '\n' {
+				next++
+			}
+		case c == '/' && next+1 < len(l.input) && l.input[next+1] == '*':
+			next += 2
+			for next+1 < len(l.input) && !(l.input[next] == '*' && l.input[next+1] == '/') {
+				next++
+			}
+			next++
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				l.pos = next + 1
+				return &Token{TokenType: AsmBlock, Literal: l.input[start+1 : next]}, nil
+			}
+		}
+	}
+	l.hitEnd = true
+	return nil, l.errorf(ErrUnterminatedAsm, start, "unterminated __asm block")
+}
+
+// readAsmLine は __asm の後ろの1行分のアセンブラを読む
+// 同じ行の次の __asm の手前, または } の手前で終わる
+func (l *Lexer) readAsmLine() *Token {
+	start := l.pos
+	next := l.pos
+	for ; next < len(l.input); next++ {
+		c := l.input[next]
+		if c == '\n' || c == '}' {
+			break
+		}
+		if c == '_' && (next == start || !isLetter(l.input[next-1]) && !isDec(l.input[next-1])) {
+			w := next
+			for w < len(l.input) && (isLetter(l.input[w]) || isDec(l.input[w])) {
+				w++
+			}
+			if tk := l.determineKeyword(l.input[next:w]); tk.TokenType == KeyAsm {
+				break
+			}
+		}
+	}
+	if next == len(l.input) {
+		// 行末まで読めていない
+		l.hitEnd = true
+	}
+	end := next
+	for end > start && (l.input[end-1] == ' ' || l.input[end-1] == '\t' || l.input[end-1] == '\r') {
+		end--
+	}
+	l.pos = end
+	return &Token{TokenType: AsmBlock, Literal: l.input[start:end]}
+}
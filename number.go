@@ -0,0 +1,229 @@
+package clanglex
+
+// readNumber は整数リテラルか浮動小数点リテラルを読む
+// ディレクティブモードでは数値の途中の行の継続を取り除いた綴りを Literal とする
+func (l *Lexer) readNumber() (*Token, error) {
+	if l.directives {
+		if s, offs := l.splicedNumber(); offs != nil {
+			hitEnd := l.hitEnd
+			tt, end, err := l.scanNumber(s, 0)
+			// s の終わりは入力の終わりではない
+			l.hitEnd = hitEnd
+			if err != nil {
+				return nil, err
+			}
+			if end < len(offs) {
+				l.pos = offs[end]
+			} else {
+				l.pos = offs[len(offs)-1] + 1
+			}
+			return &Token{TokenType: tt, Literal: s[:end]}, nil
+		}
+	}
+	tt, end, err := l.scanNumber(l.input, l.pos)
+	if err != nil {
+		return nil, err
+	}
+	w := l.input[l.pos:end]
+	l.pos = end
+	return &Token{TokenType: tt, Literal: w}, nil
+}
+
+// splicedNumber は現在位置から始まる数値の綴り(pp-number)を行の継続を除いて返す
+// offs[j] は綴りの j 文字目の入力上の位置で, 行の継続がなければ nil を返す
+func (l *Lexer) splicedNumber() (string, []int) {
+	b := []byte{}
+	offs := []int{}
+	spliced := false
+	i := l.pos
+	for i < len(l.input) {
+		if n := l.lineSplice(i); n > 0 && len(b) > 0 {
+			spliced = true
+			i += n
+			continue
+		}
+		c := l.input[i]
+		ok := isIdentChar(c) || c == '.' || c == '\'' && len(b) > 0
+		if (c == '+' || c == '-') && len(b) > 0 {
+			// 指数の符号
+			switch b[len(b)-1] {
+			case 'e', 'E', 'p', 'P':
+				ok = true
+			}
+		}
+		if !ok {
+			break
+		}
+		b = append(b, c)
+		offs = append(offs, i)
+		i++
+	}
+	if i >= len(l.input) || l.input[i] == '\\' && i+2 >= len(l.input) {
+		// 数値か行の継続が途中で切れている可能性がある
+		l.hitEnd = true
+	}
+	if !spliced {
+		return "", nil
+	}
+	return string(b), offs
+}
+
+// scanNumber は s[i:] から始まる数値リテラルを読み, 種類と終わりの位置を返す
+// 浮動小数点リテラルは 10進(1.5e-3)と16進(0x1.8p3)の両方を受け付ける
+// 数字の間の ' は桁区切り(C23)とする
+// エラーの位置はトークンの先頭(l.pos)とする
+func (l *Lexer) scanNumber(s string, i int) (TokenType, int, error) {
+	next := i
+	radix := 10
+	if s[next] == '0' && next+1 < len(s) {
+		switch s[next+1] {
+		case 'x', 'X':
+			// 16進数
+			radix = 16
+			next += 2
+		case 'b', 'B':
+			// 2進数
+			radix = 2
+			next += 2
+		default:
+			// 8進数 (小数なら 10進数)
+			radix = 8
+		}
+	}
+	hex := radix == 16
+
+	digit := isDec
+	if hex {
+		digit = isHex
+	}
+	first := next
+	next = skipDigits(s, next, digit)
+	digits := s[first:next]
+	isFloat := false
+	if radix != 2 && next < len(s) && s[next] == '.' {
+		// 小数
+		isFloat = true
+		next = skipDigits(s, next+1, digit)
+		if hex && next == first+1 {
+			return 0, 0, l.errorf(ErrMalformedNumber, l.pos, "hexadecimal floating constant has no digits")
+		}
+	}
+
+	// 指数部(10進は e, 16進は p)
+	hasExp := false
+	if radix != 2 && next < len(s) && (!hex && (s[next] == 'e' || s[next] == 'E') || hex && (s[next] == 'p' || s[next] == 'P')) {
+		e := next + 1
+		if e < len(s) && (s[e] == '+' || s[e] == '-') {
+			e++
+		}
+		if e >= len(s) || !isDec(s[e]) {
+			if e >= len(s) {
+				l.hitEnd = true
+			}
+			return 0, 0, l.errorf(ErrMalformedNumber, l.pos, "exponent has no digits")
+		}
+		isFloat = true
+		hasExp = true
+		next = skipDigits(s, e, isDec)
+	}
+	if hex && isFloat && !hasExp {
+		if next >= len(s) {
+			l.hitEnd = true
+		}
+		return 0, 0, l.errorf(ErrMalformedNumber, l.pos, "hexadecimal floating constant requires an exponent")
+	}
+
+	end := suffixEnd(s, next)
+	suffix := s[next:end]
+	if end >= len(s) {
+		// 接尾辞が途中で切れている可能性がある
+		l.hitEnd = true
+	}
+	if isFloat {
+		if !isFloatSuffix(suffix) {
+			return 0, 0, l.errorf(ErrMalformedNumber, l.pos, "invalid suffix %q on floating constant", suffix)
+		}
+		return Float, end, nil
+	}
+
+	if (radix == 16 || radix == 2) && digits == "" {
+		return 0, 0, l.errorf(ErrMalformedNumber, l.pos, "%s constant has no digits", radixName(radix))
+	}
+	if radix == 8 || radix == 2 {
+		for i := 0; i < len(digits); i++ {
+			if c := digits[i]; c != '\'' && int(c-'0') >= radix {
+				return 0, 0, l.errorf(ErrMalformedNumber, l.pos, "invalid digit '%c' in %s constant", c, radixName(radix))
+			}
+		}
+	}
+	if !intSuffixes[suffix] && !(l.dialect.Has(FeatureMSIntSuffix) && isMSIntSuffix(suffix)) {
+		return 0, 0, l.errorf(ErrMalformedNumber, l.pos, "invalid suffix %q on integer constant", suffix)
+	}
+	return Integer, end, nil
+}
+
+// skipDigits は s[i:] の先頭から digit を満たす文字と数字の間の ' (桁区切り)をとばした位置を返す
+func skipDigits(s string, i int, digit func(byte) bool) int {
+	begin := i
+	for i < len(s) {
+		if digit(s[i]) {
+			i++
+		} else if s[i] == '\'' && i > begin && i+1 < len(s) && digit(s[i+1]) {
+			i++
+		} else {
+			break
+		}
+	}
+	return i
+}
+
+// suffixEnd は s[i:] から始まる接尾辞(英数字と .)の終わりの位置を返す
+func suffixEnd(s string, i int) int {
+	for i < len(s) && (isIdentChar(s[i]) || s[i] == '.') {
+		i++
+	}
+	return i
+}
+
+func isIdentChar(c byte) bool {
+	return isLetter(c) || isDec(c)
+}
+
+func radixName(radix int) string {
+	switch radix {
+	case 2:
+		return "binary"
+	case 8:
+		return "octal"
+	case 16:
+		return "hexadecimal"
+	}
+	return "decimal"
+}
+
+// 整数リテラルの接尾辞
+// u と l, ll, wb(C23) の組み合わせで順不同, ll と wb は大文字小文字を混ぜられない
+var intSuffixes = func() map[string]bool {
+	m := map[string]bool{}
+	for _, size := range []string{"", "l", "L", "ll", "LL", "wb", "WB"} {
+		for _, u := range []string{"", "u", "U"} {
+			m[u+size] = true
+			m[size+u] = true
+		}
+	}
+	return m
+}()
+
+// isFloatSuffix は s が浮動小数点リテラルの接尾辞か返す
+func isFloatSuffix(s string) bool {
+	switch s {
+	case "", "f", "F", "l", "L",
+		// _Float16 など(C23)
+		"f16", "F16", "f32", "F32", "f64", "F64", "f128", "F128",
+		"f32x", "F32x", "f64x", "F64x", "f128x", "F128x",
+		// _Decimal32, _Decimal64, _Decimal128
+		"df", "DF", "dd", "DD", "dl", "DL":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package clanglex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFloat(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  []*Token
+	}{
+		{
+			"test exponent",
+			"1e10 1.5e-3 2E+5 6.02e23f",
+			[]*Token{
+				{TokenType: Float, Literal: "1e10"},
+				{TokenType: Float, Literal: "1.5e-3"},
+				{TokenType: Float, Literal: "2E+5"},
+				{TokenType: Float, Literal: "6.02e23f"},
+			},
+		},
+		{
+			"test leading period",
+			".5 .3211 x.y .5e2L",
+			[]*Token{
+				{TokenType: Float, Literal: ".5"},
+				{TokenType: Float, Literal: ".3211"},
+				{TokenType: Word, Literal: "x"},
+				{TokenType: Period, Literal: "."},
+				{TokenType: Word, Literal: "y"},
+				{TokenType: Float, Literal: ".5e2L"},
+			},
+		},
+		{
+			"test hex float",
+			"0x1.8p3 0X1P-2 0x.8p0f 0xA.Bp+10L",
+			[]*Token{
+				{TokenType: Float, Literal: "0x1.8p3"},
+				{TokenType: Float, Literal: "0X1P-2"},
+				{TokenType: Float, Literal: "0x.8p0f"},
+				{TokenType: Float, Literal: "0xA.Bp+10L"},
+			},
+		},
+		{
+			"test suffix",
+			"1.0f 1.0F 1.0l 2.0L 1.0F16 1.0f32x 1.0df 1.0DD 1.0dl 1e3f128",
+			[]*Token{
+				{TokenType: Float, Literal: "1.0f"},
+				{TokenType: Float, Literal: "1.0F"},
+				{TokenType: Float, Literal: "1.0l"},
+				{TokenType: Float, Literal: "2.0L"},
+				{TokenType: Float, Literal: "1.0F16"},
+				{TokenType: Float, Literal: "1.0f32x"},
+				{TokenType: Float, Literal: "1.0df"},
+				{TokenType: Float, Literal: "1.0DD"},
+				{TokenType: Float, Literal: "1.0dl"},
+				{TokenType: Float, Literal: "1e3f128"},
+			},
+		},
+		{
+			"test expression",
+			"a=1.5e-3*b-2.;",
+			[]*Token{
+				{TokenType: Word, Literal: "a"},
+				{TokenType: Assign, Literal: "="},
+				{TokenType: Float, Literal: "1.5e-3"},
+				{TokenType: Asterisk, Literal: "*"},
+				{TokenType: Word, Literal: "b"},
+				{TokenType: Minus, Literal: "-"},
+				{TokenType: Float, Literal: "2."},
+				{TokenType: Semicolon, Literal: ";"},
+			},
+		},
+		{
+			"test hex integer is not float",
+			"0x1e10 0xEf",
+			[]*Token{
+				{TokenType: Integer, Literal: "0x1e10"},
+				{TokenType: Integer, Literal: "0xEf"},
+			},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect)+1 {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect)+1)
+		}
+		for i, e := range tt.expect {
+			if got[i].TokenType != e.TokenType || got[i].Literal != e.Literal {
+				t.Errorf("got=%v %q, expect=%v %q", got[i].TokenType, got[i].Literal, e.TokenType, e.Literal)
+			}
+		}
+	}
+}
+
+func TestMalformedFloat(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		msg     string
+	}{
+		{"test exponent without digits", "x = 1e;", "1:5: exponent has no digits"},
+		{"test exponent sign without digits", "1.5e+", "1:1: exponent has no digits"},
+		{"test hex float without exponent", "0x1.8", "1:1: hexadecimal floating constant requires an exponent"},
+		{"test hex exponent without digits", "0x1.8p", "1:1: exponent has no digits"},
+		{"test bad suffix", "1.0x", `1:1: invalid suffix "x" on floating constant`},
+		{"test bad suffix 2", "1e5ff", `1:1: invalid suffix "ff" on floating constant`},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		_, err := Lexicalize(tt.src)
+		if !errors.Is(err, ErrMalformedNumber) {
+			t.Fatalf("got=%v, expect malformed number", err)
+		}
+		if err.Error() != tt.msg {
+			t.Errorf("got=%q, expect=%q", err.Error(), tt.msg)
+		}
+	}
+
+	// エラー回復モードでは数値全体が Illegal になる
+	got, err := Lexicalize("a = 1.0xyz + 2;", WithRecovery())
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	expect := []*Token{
+		{TokenType: Word, Literal: "a"},
+		{TokenType: Assign, Literal: "="},
+		{TokenType: Illegal, Literal: "1.0xyz"},
+		{TokenType: Plus, Literal: "+"},
+		{TokenType: Integer, Literal: "2"},
+		{TokenType: Semicolon, Literal: ";"},
+	}
+	for i, e := range expect {
+		if got[i].TokenType != e.TokenType || got[i].Literal != e.Literal {
+			t.Errorf("got=%v %q, expect=%v %q", got[i].TokenType, got[i].Literal, e.TokenType, e.Literal)
+		}
+	}
+}
+
+func TestInteger(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  []string
+	}{
+		{
+			"test radix",
+			"0 123 0765 0x1F 0XaB 0b0110 0B11",
+			[]string{"0", "123", "0765", "0x1F", "0XaB", "0b0110", "0B11"},
+		},
+		{
+			"test suffix",
+			"1u 1U 1l 1L 1ll 1LL 1ul 1lu 1ull 1LLU 1uLL 1Ull 1wb 1WB 1uwb 1WBU 0x1ULL",
+			[]string{"1u", "1U", "1l", "1L", "1ll", "1LL", "1ul", "1lu", "1ull", "1LLU", "1uLL", "1Ull", "1wb", "1WB", "1uwb", "1WBU", "0x1ULL"},
+		},
+		{
+			"test digit separator",
+			"1'000'000 0x7FFF'FFFF 0b1010'1010 017'7 1'000.5e1'0",
+			[]string{"1'000'000", "0x7FFF'FFFF", "0b1010'1010", "017'7", "1'000.5e1'0"},
+		},
+		{
+			"test octal prefix float",
+			"09.5 012e3",
+			[]string{"09.5", "012e3"},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect)+1 {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect)+1)
+		}
+		for i, e := range tt.expect {
+			if got[i].Literal != e {
+				t.Errorf("got=%q, expect=%q", got[i].Literal, e)
+			}
+		}
+	}
+
+	// 桁区切りの後ろが数字でなければ文字リテラル
+	got, err := Lexicalize("f(1,'a') 1'a'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []TokenType{Word, Lparen, Integer, Comma, Letter, Rparen, Integer, Letter, Eof}
+	for i, e := range expect {
+		if got[i].TokenType != e {
+			t.Errorf("%s: got type=%v, expect type=%v", got[i].Literal, got[i].TokenType, e)
+		}
+	}
+}
+
+func TestMalformedInteger(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		msg     string
+	}{
+		{"test hex digit after decimal", "x = 12ab;", `1:5: invalid suffix "ab" on integer constant`},
+		{"test octal", "09", "1:1: invalid digit '9' in octal constant"},
+		{"test octal 2", "0778", "1:1: invalid digit '8' in octal constant"},
+		{"test binary", "0b102", "1:1: invalid digit '2' in binary constant"},
+		{"test binary float", "0b1.5", `1:1: invalid suffix ".5" on integer constant`},
+		{"test hex without digits", "0x", "1:1: hexadecimal constant has no digits"},
+		{"test binary without digits", "0bu", "1:1: binary constant has no digits"},
+		{"test mixed case ll", "1lL", `1:1: invalid suffix "lL" on integer constant`},
+		{"test repeated u", "1uu", `1:1: invalid suffix "uu" on integer constant`},
+		{"test lul", "1lul", `1:1: invalid suffix "lul" on integer constant`},
+		{"test lll", "1lll", `1:1: invalid suffix "lll" on integer constant`},
+		{"test wb with l", "1wbl", `1:1: invalid suffix "wbl" on integer constant`},
+		{"test mixed case wb", "1Wb", `1:1: invalid suffix "Wb" on integer constant`},
+		{"test trailing separator", "1'", "1:2: missing terminating ' character"},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		_, err := Lexicalize(tt.src)
+		if err == nil {
+			t.Fatal("expect error")
+		}
+		if err.Error() != tt.msg {
+			t.Errorf("got=%q, expect=%q", err.Error(), tt.msg)
+		}
+	}
+}
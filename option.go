@@ -0,0 +1,11 @@
+package clanglex
+
+// Option は Lexer の動作を変更する
+type Option func(*Lexer)
+
+// WithFileName はトークンの位置情報に記録するファイル名を指定する
+func WithFileName(name string) Option {
+	return func(l *Lexer) {
+		l.file = name
+	}
+}
@@ -0,0 +1,62 @@
+package clanglex
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Position はソース上の位置を表す
+type Position struct {
+	File       string // ファイル名(指定がなければ空)
+	Offset     int    // バイトオフセット(0 始まり)
+	Line       int    // 行番号(1 始まり)
+	Column     int    // 列番号(1 始まり, バイト単位)
+	RuneColumn int    // 列番号(1 始まり, 文字単位)
+}
+
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// position はバイトオフセット off の位置を返す
+// トークンは先頭から順に生成されるので前回の位置から差分だけ数える
+// 前回より前には戻らない(ストリーミングでは前の入力を捨てているので数え直せない)
+func (l *Lexer) position(off int) Position {
+	if off > len(l.input) {
+		off = len(l.input)
+	}
+	if off < l.cur.Offset {
+		off = l.cur.Offset
+	}
+	for i := l.cur.Offset; i < off; i++ {
+		if l.base+i < l.bom {
+			continue
+		}
+		c := l.input[i]
+		if c == '\n' {
+			l.cur.Line++
+			l.cur.Column = 1
+			l.cur.RuneColumn = 1
+			continue
+		}
+		l.cur.Column++
+		if l.enc == UTF8 {
+			// UTF-8 の2バイト目以降は数えない
+			if !utf8.RuneStart(c) {
+				continue
+			}
+		} else if n := l.enc.charLen(l.input, i); n > 1 {
+			l.cur.Column += n - 1
+			i += n - 1
+		}
+		l.cur.RuneColumn++
+	}
+	l.cur.Offset = off
+	p := l.cur
+	p.File = l.file
+	p.Offset += l.base
+	return p
+}
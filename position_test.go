@@ -0,0 +1,80 @@
+package clanglex
+
+import (
+	"testing"
+)
+
+type posExpect struct {
+	literal string
+	pos     Position
+	end     Position
+}
+
+func TestPosition(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  []posExpect
+	}{
+		{
+			"test position 1",
+			"int a;\n  a = 10;\n",
+			[]posExpect{
+				{"int", Position{"hoge.c", 0, 1, 1, 1}, Position{"hoge.c", 3, 1, 4, 4}},
+				{"a", Position{"hoge.c", 4, 1, 5, 5}, Position{"hoge.c", 5, 1, 6, 6}},
+				{";", Position{"hoge.c", 5, 1, 6, 6}, Position{"hoge.c", 6, 1, 7, 7}},
+				{"a", Position{"hoge.c", 9, 2, 3, 3}, Position{"hoge.c", 10, 2, 4, 4}},
+				{"=", Position{"hoge.c", 11, 2, 5, 5}, Position{"hoge.c", 12, 2, 6, 6}},
+				{"10", Position{"hoge.c", 13, 2, 7, 7}, Position{"hoge.c", 15, 2, 9, 9}},
+				{";", Position{"hoge.c", 15, 2, 9, 9}, Position{"hoge.c", 16, 2, 10, 10}},
+				{"eof", Position{"hoge.c", 17, 3, 1, 1}, Position{"hoge.c", 17, 3, 1, 1}},
+			},
+		},
+		{
+			"test position 2",
+			"/* a\nb */ \"s\"\n# 1 \"x.c\"\nx",
+			[]posExpect{
+				{" a\nb ", Position{"hoge.c", 0, 1, 1, 1}, Position{"hoge.c", 9, 2, 5, 5}},
+				{`"s"`, Position{"hoge.c", 10, 2, 6, 6}, Position{"hoge.c", 13, 2, 9, 9}},
+				{` 1 "x.c"`, Position{"hoge.c", 14, 3, 1, 1}, Position{"hoge.c", 23, 3, 10, 10}},
+				{"x", Position{"hoge.c", 24, 4, 1, 1}, Position{"hoge.c", 25, 4, 2, 2}},
+				{"eof", Position{"hoge.c", 25, 4, 2, 2}, Position{"hoge.c", 25, 4, 2, 2}},
+			},
+		},
+		{
+			"test position utf-8",
+			"/* あ */ café = 1;",
+			[]posExpect{
+				{" あ ", Position{"hoge.c", 0, 1, 1, 1}, Position{"hoge.c", 9, 1, 10, 8}},
+				{"café", Position{"hoge.c", 10, 1, 11, 9}, Position{"hoge.c", 15, 1, 16, 13}},
+				{"=", Position{"hoge.c", 16, 1, 17, 14}, Position{"hoge.c", 17, 1, 18, 15}},
+				{"1", Position{"hoge.c", 18, 1, 19, 16}, Position{"hoge.c", 19, 1, 20, 17}},
+				{";", Position{"hoge.c", 19, 1, 20, 17}, Position{"hoge.c", 20, 1, 21, 18}},
+				{"eof", Position{"hoge.c", 20, 1, 21, 18}, Position{"hoge.c", 20, 1, 21, 18}},
+			},
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := Lexicalize(tt.src, WithFileName("hoge.c"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tt.expect) {
+			t.Fatalf("got len=%v, expect len=%v", len(got), len(tt.expect))
+		}
+		for i, v := range got {
+			e := tt.expect[i]
+			if v.Literal != e.literal {
+				t.Errorf("got literal=%v, expect literal=%v", v.Literal, e.literal)
+			}
+			if v.Pos != e.pos {
+				t.Errorf("%q: got pos=%+v, expect pos=%+v", v.Literal, v.Pos, e.pos)
+			}
+			if v.End != e.end {
+				t.Errorf("%q: got end=%+v, expect end=%+v", v.Literal, v.End, e.end)
+			}
+		}
+	}
+}
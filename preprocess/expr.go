@@ -0,0 +1,323 @@
+package preprocess
+
+import (
+	"math"
+
+	"github.com/kita127/clanglex"
+)
+
+// evalLine は #if, #elif の条件式を評価する
+func (p *Preprocessor) evalLine(dir *token, args []*token) (int64, error) {
+	// defined を先に置き換える
+	ts := []*token{}
+	for i := 0; i < len(args); i++ {
+		t := args[i]
+		if !isIdent(t) || t.Literal != "defined" {
+			ts = append(ts, t)
+			continue
+		}
+		paren := i+1 < len(args) && isPunct(args[i+1], clanglex.Lparen)
+		j := i + 1
+		if paren {
+			j++
+		}
+		if j >= len(args) || !isIdent(args[j]) {
+			return 0, errorf(t, "macro name must be an identifier")
+		}
+		name := args[j].Literal
+		if paren {
+			if j+1 >= len(args) || !isPunct(args[j+1], clanglex.Rparen) {
+				return 0, errorf(t, "missing ')' after \"defined\"")
+			}
+			j++
+		}
+		v := "0"
+		if _, ok := p.macros[name]; ok {
+			v = "1"
+		}
+		ts = append(ts, p.builtin(t, clanglex.Integer, v))
+		i = j
+	}
+
+	ts, err := p.expandAll(ts)
+	if err != nil {
+		return 0, err
+	}
+	if len(ts) == 0 {
+		return 0, errorf(dir, "#%s with no expression", dir.Literal)
+	}
+
+	e := &evaluator{toks: ts, dir: dir}
+	v, err := e.conditional()
+	if err != nil {
+		return 0, err
+	}
+	if e.pos < len(e.toks) {
+		return 0, errorf(e.toks[e.pos], "token %q is not valid in preprocessor expressions", spell(e.toks[e.pos]))
+	}
+	return v.v, nil
+}
+
+// value は条件式の値
+// C と同じく intmax_t か uintmax_t として計算する
+type value struct {
+	v        int64
+	unsigned bool
+}
+
+// evaluator は #if の条件式を再帰下降で評価する
+type evaluator struct {
+	toks []*token
+	pos  int
+	dir  *token
+	// 評価しない部分(&& や || の右辺, ?: の選ばれない方)のネストの深さ
+	// 評価しない部分では 0 除算をエラーにしない
+	skip int
+}
+
+func (e *evaluator) peek() *token {
+	if e.pos >= len(e.toks) {
+		return nil
+	}
+	return e.toks[e.pos]
+}
+
+func (e *evaluator) consume(tt clanglex.TokenType) bool {
+	if isPunct(e.peek(), tt) {
+		e.pos++
+		return true
+	}
+	return false
+}
+
+// sub は skip なら評価しない部分として f を呼ぶ
+func (e *evaluator) sub(skip bool, f func() (value, error)) (value, error) {
+	if skip {
+		e.skip++
+		defer func() { e.skip-- }()
+	}
+	return f()
+}
+
+func (e *evaluator) conditional() (value, error) {
+	c, err := e.binary(0)
+	if err != nil {
+		return value{}, err
+	}
+	if !e.consume(clanglex.Question) {
+		return c, nil
+	}
+	a, err := e.sub(c.v == 0, e.conditional)
+	if err != nil {
+		return value{}, err
+	}
+	if !e.consume(clanglex.Colon) {
+		return value{}, e.errorf("expected ':' in preprocessor expression")
+	}
+	b, err := e.sub(c.v != 0, e.conditional)
+	if err != nil {
+		return value{}, err
+	}
+	// 通常の算術型変換
+	unsigned := a.unsigned || b.unsigned
+	if c.v != 0 {
+		return value{a.v, unsigned}, nil
+	}
+	return value{b.v, unsigned}, nil
+}
+
+// 二項演算子の優先順位(大きいほど強く結合する)
+var precedence = map[clanglex.TokenType]int{
+	clanglex.Or:         1,
+	clanglex.And:        2,
+	clanglex.Vertical:   3,
+	clanglex.Caret:      4,
+	clanglex.Ampersand:  5,
+	clanglex.Eq:         6,
+	clanglex.Ne:         6,
+	clanglex.Lt:         7,
+	clanglex.Gt:         7,
+	clanglex.Lteq:       7,
+	clanglex.Gteq:       7,
+	clanglex.LeftShift:  8,
+	clanglex.RightShift: 8,
+	clanglex.Plus:       9,
+	clanglex.Minus:      9,
+	clanglex.Asterisk:   10,
+	clanglex.Slash:      10,
+	clanglex.Percent:    10,
+}
+
+func (e *evaluator) binary(min int) (value, error) {
+	lhs, err := e.unary()
+	if err != nil {
+		return value{}, err
+	}
+	for {
+		t := e.peek()
+		if t == nil {
+			return lhs, nil
+		}
+		prec, ok := precedence[t.TokenType]
+		if !ok || prec <= min {
+			return lhs, nil
+		}
+		e.pos++
+		// && と || は結果が決まれば右辺を評価しない
+		skip := t.TokenType == clanglex.And && lhs.v == 0 || t.TokenType == clanglex.Or && lhs.v != 0
+		rhs, err := e.sub(skip, func() (value, error) { return e.binary(prec) })
+		if err != nil {
+			return value{}, err
+		}
+		lhs, err = e.apply(t, lhs, rhs)
+		if err != nil {
+			return value{}, err
+		}
+	}
+}
+
+func (e *evaluator) apply(op *token, a value, b value) (value, error) {
+	// シフト以外は通常の算術型変換で両方を符号なしにそろえる
+	unsigned := a.unsigned || b.unsigned
+	ua, ub := uint64(a.v), uint64(b.v)
+	switch op.TokenType {
+	case clanglex.Or:
+		return bool2value(a.v != 0 || b.v != 0), nil
+	case clanglex.And:
+		return bool2value(a.v != 0 && b.v != 0), nil
+	case clanglex.Vertical:
+		return value{a.v | b.v, unsigned}, nil
+	case clanglex.Caret:
+		return value{a.v ^ b.v, unsigned}, nil
+	case clanglex.Ampersand:
+		return value{a.v & b.v, unsigned}, nil
+	case clanglex.Eq:
+		return bool2value(a.v == b.v), nil
+	case clanglex.Ne:
+		return bool2value(a.v != b.v), nil
+	case clanglex.Lt:
+		if unsigned {
+			return bool2value(ua < ub), nil
+		}
+		return bool2value(a.v < b.v), nil
+	case clanglex.Gt:
+		if unsigned {
+			return bool2value(ua > ub), nil
+		}
+		return bool2value(a.v > b.v), nil
+	case clanglex.Lteq:
+		if unsigned {
+			return bool2value(ua <= ub), nil
+		}
+		return bool2value(a.v <= b.v), nil
+	case clanglex.Gteq:
+		if unsigned {
+			return bool2value(ua >= ub), nil
+		}
+		return bool2value(a.v >= b.v), nil
+	case clanglex.LeftShift:
+		return value{a.v << ub, a.unsigned}, nil
+	case clanglex.RightShift:
+		if a.unsigned {
+			return value{int64(ua >> ub), true}, nil
+		}
+		return value{a.v >> ub, false}, nil
+	case clanglex.Plus:
+		return value{a.v + b.v, unsigned}, nil
+	case clanglex.Minus:
+		return value{a.v - b.v, unsigned}, nil
+	case clanglex.Asterisk:
+		return value{a.v * b.v, unsigned}, nil
+	case clanglex.Slash, clanglex.Percent:
+		if b.v == 0 {
+			if e.skip > 0 {
+				return value{0, unsigned}, nil
+			}
+			return value{}, errorf(op, "division by zero in preprocessor expression")
+		}
+		switch {
+		case op.TokenType == clanglex.Slash && unsigned:
+			return value{int64(ua / ub), true}, nil
+		case op.TokenType == clanglex.Slash:
+			return value{a.v / b.v, false}, nil
+		case unsigned:
+			return value{int64(ua % ub), true}, nil
+		}
+		return value{a.v % b.v, false}, nil
+	}
+	return value{}, errorf(op, "invalid operator %q in preprocessor expression", spell(op))
+}
+
+func (e *evaluator) unary() (value, error) {
+	t := e.peek()
+	if t == nil {
+		return value{}, e.errorf("expected value in preprocessor expression")
+	}
+	switch t.TokenType {
+	case clanglex.Plus, clanglex.Minus, clanglex.Tilde, clanglex.Bang:
+		e.pos++
+		v, err := e.unary()
+		if err != nil {
+			return value{}, err
+		}
+		switch t.TokenType {
+		case clanglex.Minus:
+			return value{-v.v, v.unsigned}, nil
+		case clanglex.Tilde:
+			return value{^v.v, v.unsigned}, nil
+		case clanglex.Bang:
+			return bool2value(v.v == 0), nil
+		}
+		return v, nil
+	case clanglex.Lparen:
+		e.pos++
+		v, err := e.conditional()
+		if err != nil {
+			return value{}, err
+		}
+		if !e.consume(clanglex.Rparen) {
+			return value{}, e.errorf("missing ')' in preprocessor expression")
+		}
+		return v, nil
+	case clanglex.Integer:
+		e.pos++
+		n, err := t.Number(nil)
+		if err != nil {
+			return value{}, errorf(t, "invalid integer %q in preprocessor expression", t.Literal)
+		}
+		v, ok := n.Uint64()
+		if !ok {
+			return value{}, errorf(t, "integer %q is too large in preprocessor expression", t.Literal)
+		}
+		// intmax_t に収まらない定数は uintmax_t とする
+		return value{int64(v), n.Type.IsUnsigned() || v > math.MaxInt64}, nil
+	case clanglex.Letter:
+		e.pos++
+		v, err := t.CharValue(nil)
+		if err != nil {
+			return value{}, errorf(t, "invalid character constant %s in preprocessor expression", spell(t))
+		}
+		return value{v, false}, nil
+	}
+	if isIdent(t) {
+		// 定義されていない識別子は 0
+		e.pos++
+		return value{}, nil
+	}
+	return value{}, errorf(t, "token %q is not valid in preprocessor expressions", spell(t))
+}
+
+func (e *evaluator) errorf(format string, a ...interface{}) error {
+	if t := e.peek(); t != nil {
+		return errorf(t, format, a...)
+	}
+	return errorf(e.dir, format, a...)
+}
+
+// bool2value は比較や論理演算の結果(int 型の 0 か 1)を返す
+func bool2value(b bool) value {
+	if b {
+		return value{1, false}
+	}
+	return value{0, false}
+}
@@ -0,0 +1,446 @@
+package preprocess
+
+import (
+	"strconv"
+
+	"github.com/kita127/clanglex"
+)
+
+type macro struct {
+	name     string
+	funcLike bool
+	params   []string
+	// 最後の引数が可変長引数(__VA_ARGS__ または GNU 形式の name...)
+	variadic bool
+	body     []*token
+}
+
+type macroArg struct {
+	raw      []*token
+	expanded []*token
+	done     bool
+}
+
+// define は #define の残りのトークンからマクロを定義する
+func (p *Preprocessor) define(args []*token) error {
+	if len(args) == 0 || !isIdent(args[0]) {
+		if len(args) > 0 {
+			return errorf(args[0], "macro names must be identifiers")
+		}
+		return errorf(&token{Token: &clanglex.Token{}}, "macro name missing")
+	}
+	name := args[0]
+	m := &macro{name: name.Literal}
+	rest := args[1:]
+	if len(rest) > 0 && isPunct(rest[0], clanglex.Lparen) && !rest[0].space {
+		m.funcLike = true
+		n, err := m.readParams(name, rest[1:])
+		if err != nil {
+			return err
+		}
+		rest = rest[1+n:]
+	}
+	m.body = rest
+	if len(m.body) > 0 {
+		if isPunct(m.body[0], clanglex.HashHash) || isPunct(m.body[len(m.body)-1], clanglex.HashHash) {
+			return errorf(name, "'##' cannot appear at either end of a macro expansion")
+		}
+	}
+	p.macros[m.name] = m
+	return nil
+}
+
+// readParams は関数形式マクロの仮引数を読み, ) までに読んだトークン数を返す
+func (m *macro) readParams(name *token, ts []*token) (int, error) {
+	i := 0
+	for {
+		if i >= len(ts) {
+			return 0, errorf(name, "missing ')' in macro parameter list")
+		}
+		t := ts[i]
+		if isPunct(t, clanglex.Rparen) && len(m.params) == 0 {
+			return i + 1, nil
+		}
+		if n := ellipsis(ts[i:]); n > 0 {
+			m.params = append(m.params, "__VA_ARGS__")
+			m.variadic = true
+			i += n
+		} else if isIdent(t) {
+			m.params = append(m.params, t.Literal)
+			i++
+			if n := ellipsis(ts[i:]); n > 0 {
+				// GNU 形式の名前付き可変長引数
+				m.variadic = true
+				i += n
+			}
+		} else {
+			return 0, errorf(t, "expected parameter name, found %q", spell(t))
+		}
+
+		if i >= len(ts) {
+			return 0, errorf(name, "missing ')' in macro parameter list")
+		}
+		if isPunct(ts[i], clanglex.Rparen) {
+			return i + 1, nil
+		}
+		if m.variadic || !isPunct(ts[i], clanglex.Comma) {
+			return 0, errorf(ts[i], "expected ',' or ')' in macro parameter list")
+		}
+		i++
+	}
+}
+
+// ellipsis は ts の先頭が ... なら 3 を返す
+func ellipsis(ts []*token) int {
+	if len(ts) < 3 {
+		return 0
+	}
+	for i := 0; i < 3; i++ {
+		if !isPunct(ts[i], clanglex.Period) || (i > 0 && ts[i].space) {
+			return 0
+		}
+	}
+	return 3
+}
+
+// expand は t がマクロなら展開した結果を s に積んで true を返す
+func (p *Preprocessor) expand(s *stream, t *token) (bool, error) {
+	if !isIdent(t) || t.hide[t.Literal] {
+		return false, nil
+	}
+
+	switch t.Literal {
+	case "__FILE__":
+		s.push(p.builtin(t, clanglex.Str, quote(t.Origin.File)))
+		return true, nil
+	case "__LINE__":
+		s.push(p.builtin(t, clanglex.Integer, strconv.Itoa(t.Origin.Line)))
+		return true, nil
+	}
+
+	m, ok := p.macros[t.Literal]
+	if !ok {
+		return false, nil
+	}
+
+	if !m.funcLike {
+		hs := t.hide.add(m.name)
+		body := []*token{}
+		for i, b := range m.body {
+			bt := p.fromMacro(b, t, hs)
+			if i == 0 {
+				bt.space = t.space
+			}
+			body = append(body, bt)
+		}
+		res, err := p.paste(body)
+		if err != nil {
+			return false, err
+		}
+		s.push(res...)
+		return true, nil
+	}
+
+	// 関数形式マクロは直後に ( がなければ展開しない
+	if !isPunct(s.peek(), clanglex.Lparen) {
+		return false, nil
+	}
+	s.next()
+	args, rparen, err := p.readArgs(s, m, t)
+	if err != nil {
+		return false, err
+	}
+	hs := t.hide.intersect(rparen.hide).add(m.name)
+	body, err := p.subst(m, args, t, hs)
+	if err != nil {
+		return false, err
+	}
+	if len(body) > 0 {
+		body[0].space = t.space
+	}
+	s.push(body...)
+	return true, nil
+}
+
+func (p *Preprocessor) builtin(t *token, tt clanglex.TokenType, lit string) *token {
+	bt := copyToken(t)
+	bt.TokenType = tt
+	bt.Literal = lit
+	bt.Raw = lit
+	return bt
+}
+
+// fromMacro はマクロ本体のトークン b を展開位置 at に置いた複製を返す
+func (p *Preprocessor) fromMacro(b *token, at *token, hs hideset) *token {
+	t := copyToken(b)
+	t.Pos = at.Pos
+	t.End = at.End
+	t.Origin = at.Origin
+	t.hide = t.hide.union(hs)
+	return t
+}
+
+// readArgs は関数形式マクロの実引数を ) まで読む
+func (p *Preprocessor) readArgs(s *stream, m *macro, name *token) ([]*macroArg, *token, error) {
+	args := []*macroArg{}
+	cur := &macroArg{}
+	depth := 0
+	var rparen *token
+	for {
+		t := s.next()
+		if t == nil || t.end != nil {
+			if t != nil {
+				s.push(t)
+			}
+			return nil, nil, errorf(name, "unterminated argument list invoking macro %q", m.name)
+		}
+		if t.TokenType == clanglex.EndDirective {
+			continue
+		}
+		if depth == 0 && isPunct(t, clanglex.Rparen) {
+			args = append(args, cur)
+			rparen = t
+			break
+		}
+		if depth == 0 && isPunct(t, clanglex.Comma) && !(m.variadic && len(args) == len(m.params)-1) {
+			args = append(args, cur)
+			cur = &macroArg{}
+			continue
+		}
+		if isPunct(t, clanglex.Lparen) {
+			depth++
+		} else if isPunct(t, clanglex.Rparen) {
+			depth--
+		}
+		cur.raw = append(cur.raw, t)
+	}
+
+	if len(m.params) == 0 && len(args) == 1 && len(args[0].raw) == 0 {
+		return nil, rparen, nil
+	}
+	if m.variadic && len(args) == len(m.params)-1 {
+		// 可変長引数の省略
+		args = append(args, &macroArg{})
+	}
+	if len(args) != len(m.params) {
+		return nil, nil, errorf(name, "macro %q passed %d arguments, but takes %d", m.name, len(args), len(m.params))
+	}
+	return args, rparen, nil
+}
+
+// subst は関数形式マクロの本体の仮引数を実引数で置き換える
+func (p *Preprocessor) subst(m *macro, args []*macroArg, at *token, hs hideset) ([]*token, error) {
+	param := func(t *token) *macroArg {
+		if t == nil || !isIdent(t) {
+			return nil
+		}
+		for i, name := range m.params {
+			if name == t.Literal {
+				return args[i]
+			}
+		}
+		return nil
+	}
+	expanded := func(a *macroArg) ([]*token, error) {
+		if !a.done {
+			ts, err := p.expandAll(a.raw)
+			if err != nil {
+				return nil, err
+			}
+			a.expanded = ts
+			a.done = true
+		}
+		return a.expanded, nil
+	}
+	var va *macroArg
+	if m.variadic {
+		va = args[len(args)-1]
+	}
+
+	body := m.body
+	res := []*token{}
+	// ## の左辺になるトークンの位置
+	pasteAt := map[int]bool{}
+	for i := 0; i < len(body); i++ {
+		b := body[i]
+		var next *token
+		if i+1 < len(body) {
+			next = body[i+1]
+		}
+
+		// __VA_OPT__(...)
+		if va != nil && b.Literal == "__VA_OPT__" && isPunct(next, clanglex.Lparen) {
+			j, depth := i+2, 1
+			for ; j < len(body); j++ {
+				if isPunct(body[j], clanglex.Lparen) {
+					depth++
+				} else if isPunct(body[j], clanglex.Rparen) {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if j >= len(body) {
+				return nil, errorf(b, "unterminated __VA_OPT__")
+			}
+			inner := []*token{}
+			if len(va.raw) > 0 {
+				inner = body[i+2 : j]
+			}
+			body = append(append(append([]*token{}, body[:i]...), inner...), body[j+1:]...)
+			i--
+			continue
+		}
+
+		// # 仮引数
+		if isPunct(b, clanglex.Hash) {
+			a := param(next)
+			if a == nil {
+				return nil, errorf(b, "'#' is not followed by a macro parameter")
+			}
+			st := p.builtin(at, clanglex.Str, quote(join(a.raw)))
+			st.space = b.space
+			res = append(res, st)
+			i++
+			continue
+		}
+
+		// GNU 拡張: , ## __VA_ARGS__
+		if va != nil && isPunct(b, clanglex.Comma) && isPunct(next, clanglex.HashHash) && i+2 < len(body) && param(body[i+2]) == va {
+			if len(va.raw) > 0 {
+				res = append(res, p.fromMacro(b, at, hs))
+				for _, t := range va.raw {
+					res = append(res, p.fromMacro(t, at, hs))
+				}
+			}
+			i += 2
+			continue
+		}
+
+		if isPunct(b, clanglex.HashHash) {
+			if a := param(next); a != nil {
+				if len(a.raw) > 0 {
+					if len(res) > 0 {
+						pasteAt[len(res)-1] = true
+					}
+					for _, t := range a.raw {
+						res = append(res, p.fromMacro(t, at, hs))
+					}
+				}
+				i++
+				continue
+			}
+			if len(res) > 0 {
+				pasteAt[len(res)-1] = true
+			}
+			continue
+		}
+
+		if a := param(b); a != nil {
+			if isPunct(next, clanglex.HashHash) {
+				// ## の左辺の仮引数は展開しない
+				if len(a.raw) == 0 {
+					// 左辺が空なら右辺だけが残る
+					i++
+					if i+1 < len(body) {
+						if ra := param(body[i+1]); ra != nil {
+							for _, t := range ra.raw {
+								res = append(res, p.fromMacro(t, at, hs))
+							}
+							i++
+						}
+					}
+					continue
+				}
+				for _, t := range a.raw {
+					res = append(res, p.fromMacro(t, at, hs))
+				}
+				continue
+			}
+			ts, err := expanded(a)
+			if err != nil {
+				return nil, err
+			}
+			for j, t := range ts {
+				et := p.fromMacro(t, at, hs)
+				if j == 0 {
+					et.space = b.space
+				}
+				res = append(res, et)
+			}
+			continue
+		}
+
+		res = append(res, p.fromMacro(b, at, hs))
+	}
+
+	// ## の連結
+	if len(pasteAt) == 0 {
+		return res, nil
+	}
+	out := []*token{}
+	for i := 0; i < len(res); i++ {
+		t := res[i]
+		for pasteAt[i] && i+1 < len(res) {
+			pt, err := p.pasteTokens(t, res[i+1])
+			if err != nil {
+				return nil, err
+			}
+			t = pt
+			i++
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// paste はオブジェクト形式マクロの本体の ## を連結する
+func (p *Preprocessor) paste(ts []*token) ([]*token, error) {
+	res := []*token{}
+	for i := 0; i < len(ts); i++ {
+		t := ts[i]
+		if isPunct(t, clanglex.HashHash) && len(res) > 0 && i+1 < len(ts) {
+			pt, err := p.pasteTokens(res[len(res)-1], ts[i+1])
+			if err != nil {
+				return nil, err
+			}
+			res[len(res)-1] = pt
+			i++
+			continue
+		}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+// pasteTokens は2つのトークンを連結して1つのトークンにする
+func (p *Preprocessor) pasteTokens(a *token, b *token) (*token, error) {
+	s := spell(a) + spell(b)
+	ts, err := clanglex.Lexicalize(s, p.cfg.Options...)
+	if err != nil || len(ts) != 2 {
+		return nil, errorf(a, "pasting %q and %q does not give a valid preprocessing token", spell(a), spell(b))
+	}
+	t := copyToken(a)
+	t.TokenType = ts[0].TokenType
+	t.Literal = ts[0].Literal
+	t.Raw = ts[0].Raw
+	return t, nil
+}
+
+// expandAll は ts を完全にマクロ展開する
+func (p *Preprocessor) expandAll(ts []*token) ([]*token, error) {
+	s := &stream{}
+	s.push(ts...)
+	res := []*token{}
+	for t := s.next(); t != nil; t = s.next() {
+		ok, err := p.expand(s, t)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,541 @@
+// Package preprocess は clanglex のトークンを使った C プリプロセッサ
+//
+// #include, #define(オブジェクト形式と関数形式, # と ##, __VA_ARGS__),
+// #if / #ifdef / #ifndef / #elif / #else / #endif, defined, #undef,
+// #pragma once, #error に対応する.
+// 出力の各トークンの Origin にはラインマーカと同じ形式で元ファイルでの位置が入る.
+package preprocess
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kita127/clanglex"
+)
+
+// Config は Preprocessor の設定
+type Config struct {
+	// #include "..." と #include <...> を検索するディレクトリ
+	IncludePaths []string
+	// システムヘッダを検索するディレクトリ
+	// ここで見つかったファイルのトークンには FlagSystemHeader が付く
+	SystemIncludePaths []string
+	// 事前に定義するマクロ(-D NAME=VALUE 相当)
+	Defines map[string]string
+	// ファイルを読む関数. nil なら os.ReadFile を使う
+	ReadFile func(name string) ([]byte, error)
+	// 字句解析のオプション(clanglex.WithDialect など)
+	// ディレクティブモード, エラー回復モードとファイル名は Preprocessor が指定する
+	Options []clanglex.Option
+}
+
+// Preprocessor は C プリプロセッサ
+type Preprocessor struct {
+	cfg    Config
+	macros map[string]*macro
+	once   map[string]bool
+	conds  []*cond
+	frames []*frame
+	in     *stream
+}
+
+type frame struct {
+	path  string
+	sys   bool
+	flags clanglex.LineFlag
+	// ファイルが見つかった検索パスの番号(#include_next で使う)
+	dir int
+	// ファイル開始時の条件ネストの深さ
+	conds int
+}
+
+type cond struct {
+	tok      *token
+	inElse   bool
+	included bool
+}
+
+const maxIncludeDepth = 200
+
+// New は Preprocessor を作る
+func New(cfg Config) *Preprocessor {
+	if cfg.ReadFile == nil {
+		cfg.ReadFile = os.ReadFile
+	}
+	return &Preprocessor{cfg: cfg}
+}
+
+// Preprocess はファイル filename をプリプロセスしたトークン列を返す
+func (p *Preprocessor) Preprocess(filename string) ([]*clanglex.Token, error) {
+	src, err := p.cfg.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return p.PreprocessString(filename, string(src))
+}
+
+// PreprocessString はファイル名 filename, 内容 src のソースをプリプロセスしたトークン列を返す
+func (p *Preprocessor) PreprocessString(filename string, src string) ([]*clanglex.Token, error) {
+	p.macros = map[string]*macro{}
+	p.once = map[string]bool{}
+	p.conds = nil
+	p.frames = nil
+	p.in = &stream{}
+
+	if err := p.predefine(); err != nil {
+		return nil, err
+	}
+
+	ts, eof, err := p.tokenize(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	p.enter(&frame{path: filename, dir: -1}, ts)
+
+	res := []*clanglex.Token{}
+	for {
+		t := p.in.next()
+		if t == nil {
+			break
+		}
+		if t.end != nil {
+			if err := p.leave(t); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if t.err != nil {
+			return nil, t.err
+		}
+		if t.bol {
+			out, err := p.directive(t)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range out {
+				res = append(res, p.emit(o))
+			}
+			continue
+		}
+		ok, err := p.expand(p.in, t)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			continue
+		}
+		res = append(res, p.emit(t))
+	}
+	res = append(res, eof)
+	return res, nil
+}
+
+// File はファイル filename を設定 cfg でプリプロセスする
+func File(filename string, cfg Config) ([]*clanglex.Token, error) {
+	return New(cfg).Preprocess(filename)
+}
+
+// predefine は組み込みマクロと Config.Defines を定義する
+func (p *Preprocessor) predefine() error {
+	src := []string{
+		"#define __STDC__ 1",
+		"#define __STDC_HOSTED__ 1",
+		"#define __STDC_VERSION__ 201710L",
+	}
+	for k, v := range p.cfg.Defines {
+		src = append(src, fmt.Sprintf("#define %s %s", k, v))
+	}
+	ts, _, err := p.tokenize("<command line>", strings.Join(src, "\n")+"\n")
+	if err != nil {
+		return err
+	}
+	s := &stream{}
+	s.push(ts...)
+	for t := s.next(); t != nil; t = s.next() {
+		line := readLine(s)
+		if err := p.define(line[1:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tokenize は src を字句解析し, コメントを除いたトークン列と Eof トークンを返す
+// 字句解析エラーは Illegal トークンに持たせ, 読み飛ばすグループの中なら無視する
+func (p *Preprocessor) tokenize(filename string, src string) ([]*token, *clanglex.Token, error) {
+	opts := append(p.cfg.Options[:len(p.cfg.Options):len(p.cfg.Options)], clanglex.WithFileName(filename), clanglex.WithDirectives(), clanglex.WithRecovery())
+	tokens, err := clanglex.Lexicalize(src, opts...)
+	errs := map[int]error{}
+	if el, ok := err.(clanglex.ErrorList); ok {
+		for _, e := range el {
+			errs[e.Pos.Offset] = e
+		}
+	} else if err != nil {
+		return nil, nil, err
+	}
+	res := []*token{}
+	var prev *clanglex.Token
+	in := false
+	for i, ct := range tokens {
+		t := &token{Token: ct}
+		t.space = prev == nil || prev.TokenType == clanglex.Comment || ct.Pos.Offset != prev.End.Offset
+		prev = ct
+		switch ct.TokenType {
+		case clanglex.Comment:
+			continue
+		case clanglex.Eof:
+			return res, ct, nil
+		case clanglex.Hash:
+			if !in && i+1 < len(tokens) {
+				switch tokens[i+1].TokenType {
+				case clanglex.Directive, clanglex.Integer, clanglex.EndDirective:
+					t.bol = true
+					in = true
+				}
+			}
+		case clanglex.EndDirective:
+			in = false
+		case clanglex.Illegal:
+			t.err = errs[ct.Pos.Offset]
+		}
+		res = append(res, t)
+	}
+	return res, nil, fmt.Errorf("%s: missing eof", filename)
+}
+
+// enter はファイルの読み込みを開始する
+func (p *Preprocessor) enter(f *frame, ts []*token) {
+	f.conds = len(p.conds)
+	if f.sys {
+		f.flags |= clanglex.FlagSystemHeader
+	}
+	p.frames = append(p.frames, f)
+	// ファイルの終わりに番兵を置く
+	sentinel := &token{Token: &clanglex.Token{}, end: f}
+	p.in.push(append(ts[:len(ts):len(ts)], sentinel)...)
+}
+
+// leave はインクルードファイルの終わりを処理する
+func (p *Preprocessor) leave(t *token) error {
+	f := t.end
+	if len(p.conds) > f.conds {
+		return errorf(p.conds[len(p.conds)-1].tok, "unterminated conditional directive")
+	}
+	p.frames = p.frames[:len(p.frames)-1]
+	if len(p.frames) > 0 {
+		parent := p.frames[len(p.frames)-1]
+		parent.flags = clanglex.FlagReturn
+		if parent.sys {
+			parent.flags |= clanglex.FlagSystemHeader
+		}
+	}
+	return nil
+}
+
+func (p *Preprocessor) current() *frame {
+	return p.frames[len(p.frames)-1]
+}
+
+// emit は出力するトークンに現在のファイルのフラグを付ける
+func (p *Preprocessor) emit(t *token) *clanglex.Token {
+	t.Origin.Flags = p.current().flags
+	return t.Token
+}
+
+// readLine はディレクティブの残りを EndDirective まで読み, EndDirective を除いて返す
+func readLine(s *stream) []*token {
+	res := []*token{}
+	for {
+		t := s.next()
+		if t == nil || t.end != nil {
+			if t != nil {
+				s.push(t)
+			}
+			return res
+		}
+		if t.TokenType == clanglex.EndDirective {
+			return res
+		}
+		res = append(res, t)
+	}
+}
+
+// directive は # から始まる行を処理する
+// 出力にそのまま残すトークン(#pragma など)を返す
+func (p *Preprocessor) directive(hash *token) ([]*token, error) {
+	line := readLine(p.in)
+	if len(line) == 0 {
+		// 空のディレクティブ
+		return nil, nil
+	}
+	name := line[0]
+	args := line[1:]
+	switch name.Literal {
+	case "elif":
+		// 評価するときに調べる
+	case "error", "warning":
+		// メッセージは字句解析できなくてもよい
+	default:
+		if err := lexError(line); err != nil {
+			return nil, err
+		}
+	}
+	if name.TokenType == clanglex.Integer {
+		// ラインマーカ(位置は字句解析器が反映済み)
+		return nil, nil
+	}
+
+	switch name.Literal {
+	case "include", "include_next", "import":
+		return nil, p.include(name, args)
+	case "define":
+		return nil, p.define(args)
+	case "undef":
+		if len(args) == 0 || !isIdent(args[0]) {
+			return nil, errorf(name, "macro name missing")
+		}
+		delete(p.macros, args[0].Literal)
+	case "if":
+		v, err := p.evalLine(name, args)
+		if err != nil {
+			return nil, err
+		}
+		return nil, p.pushCond(name, v != 0)
+	case "ifdef", "ifndef":
+		if len(args) == 0 || !isIdent(args[0]) {
+			return nil, errorf(name, "macro name missing")
+		}
+		_, ok := p.macros[args[0].Literal]
+		return nil, p.pushCond(name, ok == (name.Literal == "ifdef"))
+	case "elif":
+		c, err := p.topCond(name)
+		if err != nil {
+			return nil, err
+		}
+		if c.inElse {
+			return nil, errorf(name, "#elif after #else")
+		}
+		if c.included {
+			return nil, p.skip()
+		}
+		if err := lexError(args); err != nil {
+			return nil, err
+		}
+		v, err := p.evalLine(name, args)
+		if err != nil {
+			return nil, err
+		}
+		if v != 0 {
+			c.included = true
+			return nil, nil
+		}
+		return nil, p.skip()
+	case "else":
+		c, err := p.topCond(name)
+		if err != nil {
+			return nil, err
+		}
+		if c.inElse {
+			return nil, errorf(name, "#else after #else")
+		}
+		c.inElse = true
+		if c.included {
+			return nil, p.skip()
+		}
+		c.included = true
+	case "endif":
+		if _, err := p.topCond(name); err != nil {
+			return nil, err
+		}
+		p.conds = p.conds[:len(p.conds)-1]
+	case "pragma":
+		if len(args) == 1 && args[0].Literal == "once" {
+			p.once[p.current().path] = true
+			return nil, nil
+		}
+		end := &token{Token: &clanglex.Token{TokenType: clanglex.EndDirective, Pos: hash.Pos, End: hash.Pos, Origin: hash.Origin}}
+		return append(append([]*token{hash}, line...), end), nil
+	case "error":
+		return nil, errorf(name, "#error %s", join(args))
+	case "warning", "line", "ident", "sccs":
+		// 何もしない
+	default:
+		return nil, errorf(name, "invalid preprocessing directive #%s", name.Literal)
+	}
+	return nil, nil
+}
+
+// lexError は ts の中の最初の字句解析エラーを返す
+func lexError(ts []*token) error {
+	for _, t := range ts {
+		if t.err != nil {
+			return t.err
+		}
+	}
+	return nil
+}
+
+func (p *Preprocessor) pushCond(t *token, included bool) error {
+	p.conds = append(p.conds, &cond{tok: t, included: included})
+	if !included {
+		return p.skip()
+	}
+	return nil
+}
+
+func (p *Preprocessor) topCond(t *token) (*cond, error) {
+	if len(p.conds) <= p.current().conds {
+		return nil, errorf(t, "#%s without #if", t.Literal)
+	}
+	return p.conds[len(p.conds)-1], nil
+}
+
+// skip は対応する #elif, #else, #endif の直前までトークンを読み飛ばす
+func (p *Preprocessor) skip() error {
+	depth := 0
+	for {
+		t := p.in.next()
+		if t == nil {
+			return nil
+		}
+		if t.end != nil {
+			// ファイルの終わりは leave で検出する
+			p.in.push(t)
+			return nil
+		}
+		if !t.bol {
+			continue
+		}
+		name := p.in.peek()
+		if name == nil || name.TokenType != clanglex.Directive {
+			continue
+		}
+		switch name.Literal {
+		case "if", "ifdef", "ifndef":
+			depth++
+		case "elif", "else":
+			if depth == 0 {
+				p.in.push(t)
+				return nil
+			}
+		case "endif":
+			if depth == 0 {
+				p.in.push(t)
+				return nil
+			}
+			depth--
+		}
+		readLine(p.in)
+	}
+}
+
+// include は #include を処理する
+func (p *Preprocessor) include(dir *token, args []*token) error {
+	if len(args) > 0 && args[0].TokenType != clanglex.HeaderName && args[0].TokenType != clanglex.Str {
+		// #include MACRO
+		var err error
+		args, err = p.expandAll(args)
+		if err != nil {
+			return err
+		}
+	}
+	if len(args) == 0 {
+		return errorf(dir, "#%s expects \"FILENAME\" or <FILENAME>", dir.Literal)
+	}
+
+	var name string
+	quoted := false
+	switch {
+	case args[0].TokenType == clanglex.HeaderName:
+		name = strings.TrimSuffix(strings.TrimPrefix(args[0].Literal, "<"), ">")
+	case args[0].TokenType == clanglex.Str:
+		name = strings.TrimSuffix(strings.TrimPrefix(args[0].Literal, `"`), `"`)
+		quoted = true
+	case isPunct(args[0], clanglex.Lt):
+		// マクロ展開で < ... > になった場合
+		var b strings.Builder
+		i := 1
+		for ; i < len(args) && !isPunct(args[i], clanglex.Gt); i++ {
+			if args[i].space && i > 1 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(spell(args[i]))
+		}
+		if i == len(args) {
+			return errorf(dir, "missing terminating > character")
+		}
+		name = b.String()
+	default:
+		return errorf(dir, "#%s expects \"FILENAME\" or <FILENAME>", dir.Literal)
+	}
+
+	if len(p.frames) >= maxIncludeDepth {
+		return errorf(dir, "#include nested too deeply")
+	}
+
+	f, src, err := p.find(name, quoted, dir.Literal == "include_next")
+	if err != nil {
+		return errorf(dir, "%s: %v", name, err)
+	}
+	if p.once[f.path] {
+		return nil
+	}
+	f.flags = clanglex.FlagEnter
+	ts, _, err := p.tokenize(f.path, src)
+	if err != nil {
+		return err
+	}
+	p.enter(f, ts)
+	return nil
+}
+
+// find はインクルードファイルを探す
+func (p *Preprocessor) find(name string, quoted bool, next bool) (*frame, string, error) {
+	if filepath.IsAbs(name) {
+		src, err := p.cfg.ReadFile(name)
+		if err != nil {
+			return nil, "", err
+		}
+		return &frame{path: name, dir: -1}, string(src), nil
+	}
+
+	cur := p.current()
+	if quoted && !next {
+		path := filepath.Join(filepath.Dir(cur.path), name)
+		if src, err := p.cfg.ReadFile(path); err == nil {
+			return &frame{path: path, sys: cur.sys, dir: cur.dir}, string(src), nil
+		}
+	}
+
+	dirs := append(append([]string{}, p.cfg.IncludePaths...), p.cfg.SystemIncludePaths...)
+	start := 0
+	if next {
+		start = cur.dir + 1
+	}
+	for i := start; i < len(dirs); i++ {
+		path := filepath.Join(dirs[i], name)
+		if src, err := p.cfg.ReadFile(path); err == nil {
+			return &frame{path: path, sys: i >= len(p.cfg.IncludePaths), dir: i}, string(src), nil
+		}
+	}
+	return nil, "", fmt.Errorf("file not found")
+}
+
+// errorf は t の位置を付けたエラーを返す
+func errorf(t *token, format string, a ...interface{}) error {
+	return fmt.Errorf("%s: %s", t.Pos, fmt.Sprintf(format, a...))
+}
+
+// join はトークンの綴りを空白を考慮して連結する
+func join(ts []*token) string {
+	var b strings.Builder
+	for i, t := range ts {
+		if i > 0 && t.space {
+			b.WriteByte(' ')
+		}
+		b.WriteString(spell(t))
+	}
+	return b.String()
+}
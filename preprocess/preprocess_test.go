@@ -0,0 +1,283 @@
+package preprocess
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kita127/clanglex"
+)
+
+// memFS はテスト用のファイルシステム
+type memFS map[string]string
+
+func (m memFS) ReadFile(name string) ([]byte, error) {
+	s, ok := m[filepath.Clean(name)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(s), nil
+}
+
+// literals はトークンの綴りを空白区切りで連結する(Eof は除く)
+func literals(ts []*clanglex.Token) string {
+	res := []string{}
+	for _, t := range ts {
+		if t.TokenType == clanglex.Eof {
+			continue
+		}
+		s := t.Raw
+		if s == "" {
+			s = t.Literal
+		}
+		res = append(res, s)
+	}
+	return strings.Join(res, " ")
+}
+
+func TestPreprocess(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  string
+	}{
+		{
+			"test object-like",
+			"#define N 10\nint a[N];",
+			"int a [ 10 ] ;",
+		},
+		{
+			"test function-like",
+			"#define MAX(a, b) ((a) > (b) ? (a) : (b))\nx = MAX(1, y + 2);",
+			"x = ( ( 1 ) > ( y + 2 ) ? ( 1 ) : ( y + 2 ) ) ;",
+		},
+		{
+			"test lex error in skipped group",
+			"#if 0\ndon't \"x\n#elif 1\nx\n#elif 'a\n#else\n'\n#endif",
+			"x",
+		},
+		{
+			"test lex error in skipped else",
+			"#ifdef __STDC__\ny\n#else\n$ it's\n#endif",
+			"y",
+		},
+		{
+			"test not invoked",
+			"#define f(x) x\nint f;",
+			"int f ;",
+		},
+		{
+			"test paren needs no space",
+			"#define g (x) x\ng",
+			"( x ) x",
+		},
+		{
+			"test stringize",
+			"#define S(x) #x\nS(a  +  \"b\\n\")",
+			`"a + \"b\\n\""`,
+		},
+		{
+			"test paste",
+			"#define CAT(a, b) a ## b\n#define REG(n) REG_ ## n ## _ADDR\nCAT(foo, 12) REG(CAN0) CAT(, x) CAT(+, =)",
+			"foo12 REG_CAN0_ADDR x +=",
+		},
+		{
+			"test variadic",
+			"#define LOG(fmt, ...) printf(fmt, __VA_ARGS__)\n#define E(fmt, ...) printf(fmt, ## __VA_ARGS__)\n#define O(...) f(0 __VA_OPT__(,) __VA_ARGS__)\nLOG(\"%d %d\", 1, 2); E(\"x\"); O(); O(1)",
+			`printf ( "%d %d" , 1 , 2 ) ; printf ( "x" ) ; f ( 0 ) ; f ( 0 , 1 )`,
+		},
+		{
+			"test recursion",
+			"#define foo foo a\n#define f(x) x f\nfoo f(1)(2)",
+			"foo a 1 f ( 2 )",
+		},
+		{
+			"test rescanning",
+			"#define AA BB\n#define BB 1\n#define CALL(f, x) f(x)\n#define ID(x) x\nAA CALL(ID, 3)",
+			"1 3",
+		},
+		{
+			"test if",
+			"#define V 3\n#if V > 2 && defined(V) && !defined UNDEF\na\n#elif 1\nb\n#else\nc\n#endif",
+			"a",
+		},
+		{
+			"test elif",
+			"#if 0\na\n#if 1\nx\n#endif\n#elif (1 << 3) == 8 ? 0 : 1\nb\n#elif 'A' == 65\nc\n#else\nd\n#endif",
+			"c",
+		},
+		{
+			"test if number",
+			"#if 0B1010 == 10 && 1'000 == 1000 && 0xFFFFFFFFull > 0 && 0x10wb == 16\na\n#endif",
+			"a",
+		},
+		{
+			"test if short circuit",
+			"#define N 0\n#if 0 && (1 / 0)\na\n#elif N != 0 && 100 / N > 2\nb\n#elif 1 || 1 % 0\nc\n#endif\n#if N ? 1 / N : 1\nd\n#endif",
+			"c d",
+		},
+		{
+			"test if unsigned",
+			"#if -1 > 0u\na\n#endif\n#if -1 < 0\nb\n#endif\n#if (0u - 1) >> 63 == 1 && -1 >> 63 == -1\nc\n#endif\n#if 0xFFFFFFFFFFFFFFFF > 0 && (1 ? -1 : 0u) > 0\nd\n#endif",
+			"a b c d",
+		},
+		{
+			"test ifdef",
+			"#define X\n#ifdef X\na\n#endif\n#ifndef X\nb\n#else\nc\n#endif\n#undef X\n#ifdef X\nd\n#endif",
+			"a c",
+		},
+		{
+			"test pragma",
+			"#pragma section \"abc\"\nint a;",
+			`# pragma section "abc"  int a ;`,
+		},
+		{
+			"test builtin",
+			"__LINE__ __FILE__\n#if __STDC__ && __STDC_VERSION__ >= 199901L\nc99\n#endif",
+			`1 "main.c" c99`,
+		},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		got, err := New(Config{ReadFile: memFS{}.ReadFile}).PreprocessString("main.c", tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := literals(got); s != tt.expect {
+			t.Errorf("got=%q, expect=%q", s, tt.expect)
+		}
+	}
+}
+
+func TestInclude(t *testing.T) {
+	fs := memFS{
+		"src/main.c":             "#include \"can.h\"\n#include <stdint.h>\n#include HDR\nuint8_t v = CAN_ID;",
+		"src/can.h":              "#pragma once\n#include \"can.h\"\n#define CAN_ID 0x100\nint can;",
+		"inc/stdint.h":           "#ifndef STDINT_H\n#define STDINT_H\ntypedef unsigned char uint8_t;\n#endif",
+		"sys/stdint.h":           "sys",
+		"inc/other/stdint_ext.h": "ext;",
+	}
+	cfg := Config{
+		IncludePaths:       []string{"inc"},
+		SystemIncludePaths: []string{"sys"},
+		Defines:            map[string]string{"HDR": "<other/stdint_ext.h>"},
+		ReadFile:           fs.ReadFile,
+	}
+	got, err := File("src/main.c", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "int can ; typedef unsigned char uint8_t ; ext ; uint8_t v = 0x100 ;"
+	if s := literals(got); s != expect {
+		t.Fatalf("got=%q, expect=%q", s, expect)
+	}
+
+	origins := []clanglex.Origin{
+		{File: "src/can.h", Line: 4, Flags: clanglex.FlagEnter},
+		{File: "inc/stdint.h", Line: 3, Flags: clanglex.FlagEnter},
+		{File: "inc/other/stdint_ext.h", Line: 1, Flags: clanglex.FlagEnter},
+		{File: "src/main.c", Line: 4, Flags: clanglex.FlagReturn},
+	}
+	for i, idx := range []int{0, 3, 9, 11} {
+		if got[idx].Origin != origins[i] {
+			t.Errorf("%q: got origin=%+v, expect=%+v", got[idx].Literal, got[idx].Origin, origins[i])
+		}
+	}
+	if last := got[len(got)-3]; last.Literal != "0x100" || last.Origin.File != "src/main.c" || last.Origin.Line != 4 {
+		t.Errorf("got %q origin=%+v", last.Literal, last.Origin)
+	}
+}
+
+func TestSystemInclude(t *testing.T) {
+	fs := memFS{
+		"main.c":       "#include <stdio.h>\nint a;",
+		"sys/stdio.h":  "#include_next <stdio.h>\nint printf;",
+		"sys2/stdio.h": "int puts;",
+	}
+	got, err := File("main.c", Config{SystemIncludePaths: []string{"sys", "sys2"}, ReadFile: fs.ReadFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := literals(got); s != "int puts ; int printf ; int a ;" {
+		t.Fatalf("got=%q", s)
+	}
+	user := clanglex.UserTokens(got)
+	if s := literals(user); s != "int a ;" {
+		t.Errorf("got=%q", s)
+	}
+}
+
+func TestPreprocessError(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		expect  string
+	}{
+		{"test error", "#error stop here", "main.c:1:2: #error stop here"},
+		{"test unterminated if", "#if 1\na", "main.c:1:2: unterminated conditional directive"},
+		{"test else without if", "#else", "main.c:1:2: #else without #if"},
+		{"test not found", "#include \"none.h\"", "main.c:1:2: none.h: file not found"},
+		{"test args", "#define f(a, b) a\nf(1)", "main.c:2:1: macro \"f\" passed 1 arguments, but takes 2"},
+		{"test unterminated args", "#define f(a) a\nf(1", "main.c:2:1: unterminated argument list invoking macro \"f\""},
+		{"test div zero", "#if 1 / 0\n#endif", "main.c:1:7: division by zero in preprocessor expression"},
+		{"test lex error", "#if 1\ndon't\n#endif", "main.c:2:4: missing terminating ' character"},
+		{"test lex error in directive", "#define C 'a\nC", "main.c:1:11: missing terminating ' character"},
+		{"test lex error in elif", "#if 0\n#elif 'a\n#endif", "main.c:2:7: missing terminating ' character"},
+		{"test error apostrophe", "#error don't stop", "main.c:1:2: #error don't stop"},
+	}
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		_, err := New(Config{ReadFile: memFS{}.ReadFile}).PreprocessString("main.c", tt.src)
+		if err == nil {
+			t.Fatalf("expect error %q", tt.expect)
+		}
+		if err.Error() != tt.expect {
+			t.Errorf("got=%q, expect=%q", err.Error(), tt.expect)
+		}
+	}
+}
+
+func TestPreprocessDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "include"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "include", "cfg.h"), []byte("#define SIZE 4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(dir, "main.c")
+	if err := os.WriteFile(main, []byte("#include <cfg.h>\nint a[SIZE];\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := File(main, Config{IncludePaths: []string{filepath.Join(dir, "include")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := literals(got); s != "int a [ 4 ] ;" {
+		t.Errorf("got=%q", s)
+	}
+}
+
+func TestPreprocessDialect(t *testing.T) {
+	src := "#define CAT(a, b) a##b\nbool x; CAT(__in, t64) y;\n__asm { mov eax, 1 }\n"
+	cfg := Config{ReadFile: memFS{}.ReadFile, Options: []clanglex.Option{clanglex.WithDialect(clanglex.MSVC(clanglex.C23))}}
+	got, err := New(cfg).PreprocessString("main.c", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []clanglex.TokenType{
+		clanglex.KeyBool, clanglex.Word, clanglex.Semicolon,
+		clanglex.KeyInt64, clanglex.Word, clanglex.Semicolon,
+		clanglex.KeyAsm, clanglex.AsmBlock, clanglex.Eof,
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("got %q, expect %d tokens", literals(got), len(expect))
+	}
+	for i, v := range got {
+		if v.TokenType != expect[i] {
+			t.Errorf("%q: got type=%v, expect type=%v", v.Literal, v.TokenType, expect[i])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package preprocess
+
+import (
+	"strings"
+
+	"github.com/kita127/clanglex"
+)
+
+// token はプリプロセス中のトークン
+type token struct {
+	*clanglex.Token
+	// 直前に空白がある
+	space bool
+	// 行頭の # (ディレクティブの開始)
+	bol bool
+	// このトークンを展開してはならないマクロ名の集合
+	hide hideset
+	// インクルードファイルの終わりを表す番兵
+	end *frame
+	// Illegal トークンの字句解析エラー
+	err error
+}
+
+type hideset map[string]bool
+
+func (h hideset) union(o hideset) hideset {
+	res := hideset{}
+	for k := range h {
+		res[k] = true
+	}
+	for k := range o {
+		res[k] = true
+	}
+	return res
+}
+
+func (h hideset) intersect(o hideset) hideset {
+	res := hideset{}
+	for k := range h {
+		if o[k] {
+			res[k] = true
+		}
+	}
+	return res
+}
+
+func (h hideset) add(name string) hideset {
+	return h.union(hideset{name: true})
+}
+
+// copyToken は t の複製を返す
+func copyToken(t *token) *token {
+	ct := *t.Token
+	return &token{Token: &ct, space: t.space, hide: t.hide, err: t.err}
+}
+
+// spell はトークンのソース上の綴りを返す
+func spell(t *token) string {
+	if t.Raw != "" {
+		return t.Raw
+	}
+	return t.Literal
+}
+
+// isIdent は識別子(キーワードを含む)か返す
+func isIdent(t *token) bool {
+	switch t.TokenType {
+	case clanglex.Str, clanglex.Letter, clanglex.Comment, clanglex.HeaderName,
+		clanglex.Directive, clanglex.Integer, clanglex.Float, clanglex.Eof:
+		return false
+	}
+	s := t.Literal
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isPunct(t *token, tt clanglex.TokenType) bool {
+	return t != nil && t.end == nil && t.TokenType == tt
+}
+
+// stream は読み出し待ちのトークンを逆順に積んだスタック
+type stream struct {
+	stack []*token
+}
+
+func (s *stream) next() *token {
+	if len(s.stack) == 0 {
+		return nil
+	}
+	t := s.stack[len(s.stack)-1]
+	s.stack = s.stack[:len(s.stack)-1]
+	return t
+}
+
+func (s *stream) peek() *token {
+	if len(s.stack) == 0 {
+		return nil
+	}
+	return s.stack[len(s.stack)-1]
+}
+
+// push は ts を先頭から読み出されるように積む
+func (s *stream) push(ts ...*token) {
+	for i := len(ts) - 1; i >= 0; i-- {
+		s.stack = append(s.stack, ts[i])
+	}
+}
+
+// quote は s を C の文字列リテラルにする
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
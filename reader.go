@@ -0,0 +1,85 @@
+package clanglex
+
+import (
+	"io"
+)
+
+const (
+	// io.Reader から一度に読むバイト数
+	readChunk = 64 * 1024
+	// トークンの末尾からこのバイト数以内に読み込んだ入力の終わりがあれば読み足して字句解析をやり直す
+	lookahead = 4
+)
+
+// NewReaderLexer は r から読みながら字句解析する Lexer を作る
+// 字句解析済みの入力は捨てるのでメモリ使用量はトークンの大きさ程度に収まる
+// トークンは Next で1つずつ取り出す
+func NewReaderLexer(r io.Reader, opts ...Option) *Lexer {
+	l := NewLexer("", opts...)
+	l.r = r
+	l.chunk = readChunk
+	return l
+}
+
+// Next は次のトークンを返す
+// 入力の終わりでは Eof トークンを返し, 以降も Eof トークンを返し続ける
+// 入力がバイナリなら ErrBinary を返す
+// WithRecovery を指定した場合, 字句解析エラーは Illegal トークンになり Errors に記録される
+func (l *Lexer) Next() (*Token, error) {
+	if !l.started {
+		if err := l.start(); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		t, err := l.next()
+		if err != nil {
+			if t, err = l.recoverError(err); err != nil {
+				return nil, err
+			}
+		}
+		if l.userOnly && t.IsSystem(l.systemPrefixes...) {
+			continue
+		}
+		return t, nil
+	}
+}
+
+func (l *Lexer) next() (*Token, error) {
+	if l.r == nil {
+		return l.nextToken()
+	}
+	for {
+		l.hitEnd = false
+		saved := *l
+		t, err := l.nextToken()
+		if l.eof || (!l.hitEnd && l.pos+lookahead <= len(l.input)) {
+			return t, err
+		}
+		// 入力が足りない可能性があるので読み足してやり直す
+		*l = saved
+		if err := l.fill(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fill は字句解析済みの入力を捨て, r から読み足す
+func (l *Lexer) fill() error {
+	k := l.pos
+	if l.cur.Offset < k {
+		k = l.cur.Offset
+	}
+	buf := make([]byte, l.chunk)
+	n, err := io.ReadFull(l.r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		l.eof = true
+	} else if err != nil {
+		return err
+	}
+	l.input = l.input[k:] + string(buf[:n])
+	l.base += k
+	l.pos -= k
+	l.cur.Offset -= k
+	return nil
+}
@@ -0,0 +1,135 @@
+package clanglex
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReaderLexer(t *testing.T) {
+	src := `# 1 "hoge.c"
+#include <stdio.h>
+#define MAX(a, b) \
+    ((a) > (b) ? (a) : (b))
+/* block
+   comment */
+int g_var = 0x1F; // line comment
+static unsigned long s_var = (long)(100U);
+char *s = "hello \"world\"\\";
+char c = '\'';
+# 20 "fuga.h" 1 3
+int main(void){
+    g_var <<= 2; g_var >>= s_var;
+    return g_var->x ... -- ++ && ||;
+}
+`
+	testTbl := []struct {
+		comment string
+		src     string
+		opts    []Option
+	}{
+		{"test default", src, nil},
+		{"test directives", src, []Option{WithDirectives()}},
+		{"test user files", src, []Option{WithUserFilesOnly()}},
+		{"test msvc", "__int64 a = 100ui64;\n__asm {\n mov eax, 1 ; }\n}\n__asm mov eax, 1 __asm int 3\nx;", []Option{WithDialect(MSVC(C99))}},
+		{"test numbers", "x = 1.5e-3 + .5 * 0x1.8p3f - 6.02e23L / 100UL + 0b1010'1010 + 0X7fffu + 1'000'000ull;", nil},
+		{"test prefix", "wchar_t *s = L\"wide\"; char16_t c = u'a'; const char *t = u8\"x\" U\"y\"; L = u8;", nil},
+		{"test splices", "#define AB\\\ncd 12\\\r\n34 1e\\\n+5 u\\\n8\"s\"\nx\\\ny 0x\\\n\\\n1", []Option{WithDirectives()}},
+		{"test recovery", "int $ a = \"abc\n@ b; /* x", []Option{WithRecovery()}},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		opts := append([]Option{WithFileName("hoge.c")}, tt.opts...)
+		expect, err := Lexicalize(tt.src, opts...)
+		var el ErrorList
+		if err != nil && !errors.As(err, &el) {
+			t.Fatal(err)
+		}
+
+		for chunk := 1; chunk < 20; chunk++ {
+			l := NewReaderLexer(iotest.HalfReader(strings.NewReader(tt.src)), opts...)
+			l.chunk = chunk
+			for i := 0; ; i++ {
+				got, err := l.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i >= len(expect) {
+					t.Fatalf("chunk=%d: too many tokens %v", chunk, got)
+				}
+				e := expect[i]
+				if got.TokenType != e.TokenType || got.Literal != e.Literal || got.Raw != e.Raw {
+					t.Fatalf("chunk=%d: got=%v, expect=%v", chunk, got, e)
+				}
+				if got.Pos != e.Pos || got.End != e.End || got.Origin != e.Origin {
+					t.Fatalf("chunk=%d: %q got pos=%+v-%+v %+v, expect pos=%+v-%+v %+v", chunk, got.Literal, got.Pos, got.End, got.Origin, e.Pos, e.End, e.Origin)
+				}
+				if got.TokenType == Eof {
+					break
+				}
+			}
+			if len(l.Errors()) != len(el) {
+				t.Errorf("chunk=%d: got %d errors, expect %d", chunk, len(l.Errors()), len(el))
+			}
+		}
+	}
+}
+
+func TestReaderLexerError(t *testing.T) {
+	l := NewReaderLexer(strings.NewReader("int a;\n$"), WithFileName("hoge.c"))
+	for {
+		_, err := l.Next()
+		if err != nil {
+			if !errors.Is(err, ErrUnknownChar) || err.Error() != "hoge.c:2:1: unknown character '$'" {
+				t.Errorf("got=%v", err)
+			}
+			break
+		}
+	}
+
+	l = NewReaderLexer(iotest.ErrReader(errors.New("read error")))
+	if _, err := l.Next(); err == nil || err.Error() != "read error" {
+		t.Errorf("got=%v", err)
+	}
+}
+
+// countReader は読んだバイト数を数える
+type countReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestReaderLexerMemory(t *testing.T) {
+	src := strings.Repeat("#define X 1\nint a;\n", 100000)
+	testTbl := []struct {
+		comment string
+		opts    []Option
+	}{
+		{"test default", nil},
+		{"test directives", []Option{WithDirectives()}},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		r := &countReader{r: strings.NewReader(src)}
+		l := NewReaderLexer(r, tt.opts...)
+		for i := 0; i < 100; i++ {
+			if _, err := l.Next(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// 100 トークン読むまでに読み込むのは1回分だけ
+		if r.n > readChunk {
+			t.Errorf("got read=%d bytes, expect <= %d", r.n, readChunk)
+		}
+	}
+}
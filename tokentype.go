@@ -0,0 +1,162 @@
+package clanglex
+
+import (
+	"fmt"
+	"sync"
+)
+
+//go:generate go run gen_tokentype.go
+
+// TokenType はトークンの種類
+type TokenType int
+
+// Category はトークンの種類の分類
+type Category int
+
+const (
+	CategoryOther      Category = iota // Eof, ディレクティブ名など
+	CategoryIdentifier                 // 識別子
+	CategoryKeyword                    // キーワード
+	CategoryPunctuator                 // 区切り子
+	CategoryLiteral                    // 数値, 文字, 文字列のリテラル
+	CategoryTrivia                     // コメント
+)
+
+var categoryNames = [...]string{
+	CategoryOther:      "other",
+	CategoryIdentifier: "identifier",
+	CategoryKeyword:    "keyword",
+	CategoryPunctuator: "punctuator",
+	CategoryLiteral:    "literal",
+	CategoryTrivia:     "trivia",
+}
+
+func (c Category) String() string {
+	if 0 <= c && int(c) < len(categoryNames) {
+		return categoryNames[c]
+	}
+	return fmt.Sprintf("Category(%d)", int(c))
+}
+
+// 利用者が割り当てたトークンの種類
+var customTypes struct {
+	sync.RWMutex
+	names      []string
+	categories []Category
+	byName     map[string]TokenType
+}
+
+// NewTokenType は name という名前で分類が c のトークンの種類を新しく割り当てる
+// 割り当てた種類は組込みのトークンの種類と重ならない
+// name が既にあればその種類を返す
+func NewTokenType(name string, c Category) TokenType {
+	if tt, ok := tokenTypeByName[name]; ok {
+		return tt
+	}
+	customTypes.Lock()
+	defer customTypes.Unlock()
+	if tt, ok := customTypes.byName[name]; ok {
+		return tt
+	}
+	if customTypes.byName == nil {
+		customTypes.byName = map[string]TokenType{}
+	}
+	tt := numTokenTypes + TokenType(len(customTypes.names))
+	customTypes.names = append(customTypes.names, name)
+	customTypes.categories = append(customTypes.categories, c)
+	customTypes.byName[name] = tt
+	return tt
+}
+
+// String は tt の名前を返す(Eof, KeyInt など定数と同じ綴り)
+func (tt TokenType) String() string {
+	if 0 <= tt && tt < numTokenTypes {
+		return tokenTypeNames[tt]
+	}
+	customTypes.RLock()
+	defer customTypes.RUnlock()
+	if i := int(tt - numTokenTypes); 0 <= i && i < len(customTypes.names) {
+		return customTypes.names[i]
+	}
+	return fmt.Sprintf("TokenType(%d)", int(tt))
+}
+
+// Category は tt の分類を返す
+func (tt TokenType) Category() Category {
+	if 0 <= tt && tt < numTokenTypes {
+		return tokenTypeCategories[tt]
+	}
+	customTypes.RLock()
+	defer customTypes.RUnlock()
+	if i := int(tt - numTokenTypes); 0 <= i && i < len(customTypes.categories) {
+		return customTypes.categories[i]
+	}
+	return CategoryOther
+}
+
+// IsKeyword はキーワードか返す
+func (tt TokenType) IsKeyword() bool {
+	return tt.Category() == CategoryKeyword
+}
+
+// IsPunctuator は区切り子か返す
+func (tt TokenType) IsPunctuator() bool {
+	return tt.Category() == CategoryPunctuator
+}
+
+// IsLiteral はリテラルか返す
+func (tt TokenType) IsLiteral() bool {
+	return tt.Category() == CategoryLiteral
+}
+
+// IsTrivia はコメントなど構文上意味を持たないトークンか返す
+func (tt TokenType) IsTrivia() bool {
+	return tt.Category() == CategoryTrivia
+}
+
+// ParseTokenType は String が返す名前からトークンの種類を返す
+func ParseTokenType(name string) (TokenType, error) {
+	if tt, ok := tokenTypeByName[name]; ok {
+		return tt, nil
+	}
+	customTypes.RLock()
+	defer customTypes.RUnlock()
+	if tt, ok := customTypes.byName[name]; ok {
+		return tt, nil
+	}
+	return 0, fmt.Errorf("clanglex: unknown token type %q", name)
+}
+
+// TokenTypes は全てのトークンの種類を返す(NewTokenType で割り当てた種類を含む)
+func TokenTypes() []TokenType {
+	customTypes.RLock()
+	defer customTypes.RUnlock()
+	ts := make([]TokenType, 0, int(numTokenTypes)+len(customTypes.names))
+	for tt := TokenType(0); tt < numTokenTypes+TokenType(len(customTypes.names)); tt++ {
+		ts = append(ts, tt)
+	}
+	return ts
+}
+
+// MarshalText は tt を名前にする
+func (tt TokenType) MarshalText() ([]byte, error) {
+	return []byte(tt.String()), nil
+}
+
+// UnmarshalText は名前から tt を復元する
+func (tt *TokenType) UnmarshalText(text []byte) error {
+	v, err := ParseTokenType(string(text))
+	if err != nil {
+		return err
+	}
+	*tt = v
+	return nil
+}
+
+var tokenTypeByName = func() map[string]TokenType {
+	m := make(map[string]TokenType, len(tokenTypeNames))
+	for i, name := range tokenTypeNames {
+		m[name] = TokenType(i)
+	}
+	return m
+}()
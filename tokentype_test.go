@@ -0,0 +1,106 @@
+package clanglex
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTokenTypeNames(t *testing.T) {
+	seen := map[string]bool{}
+	for _, tt := range TokenTypes() {
+		name := tt.String()
+		if name == "" || seen[name] {
+			t.Fatalf("%d: bad name %q", int(tt), name)
+		}
+		seen[name] = true
+
+		got, err := ParseTokenType(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt {
+			t.Errorf("%s: got=%d, expect=%d", name, int(got), int(tt))
+		}
+	}
+	if len(seen) < int(numTokenTypes) {
+		t.Errorf("got %d types, expect at least %d", len(seen), int(numTokenTypes))
+	}
+
+	if _, err := ParseTokenType("NoSuchType"); err == nil {
+		t.Errorf("expect error")
+	}
+	if s := TokenType(-1).String(); s != "TokenType(-1)" {
+		t.Errorf("got=%q", s)
+	}
+}
+
+func TestTokenTypeCategory(t *testing.T) {
+	testTbl := []struct {
+		tt     TokenType
+		name   string
+		expect Category
+	}{
+		{Eof, "Eof", CategoryOther},
+		{Word, "Word", CategoryIdentifier},
+		{Integer, "Integer", CategoryLiteral},
+		{Str, "Str", CategoryLiteral},
+		{Letter, "Letter", CategoryLiteral},
+		{PlusAssigne, "PlusAssigne", CategoryPunctuator},
+		{Arrow, "Arrow", CategoryPunctuator},
+		{At, "At", CategoryPunctuator},
+		{KeyInt, "KeyInt", CategoryKeyword},
+		{KeyAt, "KeyAt", CategoryKeyword},
+		{Comment, "Comment", CategoryTrivia},
+		{Directive, "Directive", CategoryOther},
+		{Illegal, "Illegal", CategoryOther},
+	}
+
+	for _, tt := range testTbl {
+		if tt.tt.String() != tt.name {
+			t.Errorf("got=%q, expect=%q", tt.tt.String(), tt.name)
+		}
+		if tt.tt.Category() != tt.expect {
+			t.Errorf("%s: got=%v, expect=%v", tt.name, tt.tt.Category(), tt.expect)
+		}
+	}
+
+	if !KeyStatic.IsKeyword() || !Semicolon.IsPunctuator() || !Float.IsLiteral() || !Comment.IsTrivia() || Word.IsKeyword() {
+		t.Errorf("unexpected category")
+	}
+
+	custom := NewTokenType("KeyCustom", CategoryKeyword)
+	if custom != NewTokenType("KeyCustom", CategoryKeyword) || !custom.IsKeyword() {
+		t.Errorf("unexpected custom type %v", custom)
+	}
+	if got, err := ParseTokenType("KeyCustom"); err != nil || got != custom {
+		t.Errorf("got=%v, %v", got, err)
+	}
+	if NewTokenType("KeyInt", CategoryKeyword) != KeyInt {
+		t.Errorf("builtin name must return builtin type")
+	}
+}
+
+func TestTokenTypeJSON(t *testing.T) {
+	ts, err := Lexicalize("int a = 1;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*Token
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range ts {
+		if got[i].TokenType != e.TokenType || got[i].Literal != e.Literal || got[i].Pos != e.Pos {
+			t.Errorf("got=%v, expect=%v", got[i], e)
+		}
+	}
+
+	var tt TokenType
+	if err := json.Unmarshal([]byte(`"Unknown"`), &tt); err == nil {
+		t.Errorf("expect error")
+	}
+}
@@ -0,0 +1,353 @@
+package clanglex
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DataModel は C の整数型のビット数
+type DataModel struct {
+	Name         string
+	CharBits     int
+	ShortBits    int
+	IntBits      int
+	LongBits     int
+	LongLongBits int
+	WcharBits    int
+	UnsignedChar bool // char が符号なし
+}
+
+var (
+	// ILP32 は int, long, ポインタが 32 ビットのモデル (32 ビットの Linux, Windows, 多くの 32 ビットマイコン)
+	ILP32 = &DataModel{Name: "ILP32", CharBits: 8, ShortBits: 16, IntBits: 32, LongBits: 32, LongLongBits: 64, WcharBits: 32}
+	// LP64 は long とポインタが 64 ビットのモデル (64 ビットの Linux, macOS)
+	LP64 = &DataModel{Name: "LP64", CharBits: 8, ShortBits: 16, IntBits: 32, LongBits: 64, LongLongBits: 64, WcharBits: 32}
+	// LLP64 は long long とポインタが 64 ビットのモデル (64 ビットの Windows)
+	LLP64 = &DataModel{Name: "LLP64", CharBits: 8, ShortBits: 16, IntBits: 32, LongBits: 32, LongLongBits: 64, WcharBits: 16}
+	// IP16 は int が 16 ビットのモデル (RL78, MSP430, 8051 など)
+	IP16 = &DataModel{Name: "IP16", CharBits: 8, ShortBits: 16, IntBits: 16, LongBits: 32, LongLongBits: 64, WcharBits: 16}
+)
+
+// CType は数値リテラルの C の型
+type CType int
+
+const (
+	TypeInvalid CType = iota
+	TypeChar
+	TypeUnsignedChar
+	TypeShort
+	TypeUnsignedShort
+	TypeInt
+	TypeUnsignedInt
+	TypeLong
+	TypeUnsignedLong
+	TypeLongLong
+	TypeUnsignedLongLong
+	TypeBitInt
+	TypeUnsignedBitInt
+	TypeFloat
+	TypeDouble
+	TypeLongDouble
+	TypeFloat16
+	TypeFloat32
+	TypeFloat64
+	TypeFloat128
+	TypeFloat32x
+	TypeFloat64x
+	TypeFloat128x
+	TypeDecimal32
+	TypeDecimal64
+	TypeDecimal128
+)
+
+var ctypeNames = [...]string{
+	TypeInvalid:          "invalid",
+	TypeChar:             "char",
+	TypeUnsignedChar:     "unsigned char",
+	TypeShort:            "short",
+	TypeUnsignedShort:    "unsigned short",
+	TypeInt:              "int",
+	TypeUnsignedInt:      "unsigned int",
+	TypeLong:             "long",
+	TypeUnsignedLong:     "unsigned long",
+	TypeLongLong:         "long long",
+	TypeUnsignedLongLong: "unsigned long long",
+	TypeBitInt:           "_BitInt",
+	TypeUnsignedBitInt:   "unsigned _BitInt",
+	TypeFloat:            "float",
+	TypeDouble:           "double",
+	TypeLongDouble:       "long double",
+	TypeFloat16:          "_Float16",
+	TypeFloat32:          "_Float32",
+	TypeFloat64:          "_Float64",
+	TypeFloat128:         "_Float128",
+	TypeFloat32x:         "_Float32x",
+	TypeFloat64x:         "_Float64x",
+	TypeFloat128x:        "_Float128x",
+	TypeDecimal32:        "_Decimal32",
+	TypeDecimal64:        "_Decimal64",
+	TypeDecimal128:       "_Decimal128",
+}
+
+func (c CType) String() string {
+	if 0 <= c && int(c) < len(ctypeNames) {
+		return ctypeNames[c]
+	}
+	return fmt.Sprintf("CType(%d)", int(c))
+}
+
+// IsUnsigned は符号なし整数型か返す
+func (c CType) IsUnsigned() bool {
+	switch c {
+	case TypeUnsignedChar, TypeUnsignedShort, TypeUnsignedInt, TypeUnsignedLong, TypeUnsignedLongLong, TypeUnsignedBitInt:
+		return true
+	}
+	return false
+}
+
+// Bits はデータモデル m での整数型 c のビット数を返す(浮動小数点型と _BitInt は 0)
+func (m *DataModel) Bits(c CType) int {
+	switch c {
+	case TypeChar, TypeUnsignedChar:
+		return m.CharBits
+	case TypeShort, TypeUnsignedShort:
+		return m.ShortBits
+	case TypeInt, TypeUnsignedInt:
+		return m.IntBits
+	case TypeLong, TypeUnsignedLong:
+		return m.LongBits
+	case TypeLongLong, TypeUnsignedLongLong:
+		return m.LongLongBits
+	}
+	return 0
+}
+
+// Number は数値リテラルを解釈した結果
+type Number struct {
+	Radix  int    // 2, 8, 10, 16
+	Digits string // 接頭辞, 桁区切り, 接尾辞を除いた綴り (0x1.8p3 なら 1.8p3)
+	Suffix string
+	Type   CType
+	Width  int // _BitInt(N) の N, 整数型ならデータモデルでのビット数
+
+	integer *big.Int
+	float   *big.Rat
+}
+
+// IsFloat は浮動小数点リテラルか返す
+func (n *Number) IsFloat() bool {
+	return n.float != nil
+}
+
+// Int は整数リテラルの値を返す(浮動小数点リテラルなら小数部を切り捨てた値)
+func (n *Number) Int() *big.Int {
+	if n.float != nil {
+		return new(big.Int).Quo(n.float.Num(), n.float.Denom())
+	}
+	return new(big.Int).Set(n.integer)
+}
+
+// Uint64 は値を uint64 で返す. 整数で uint64 に収まる場合のみ ok が true になる
+func (n *Number) Uint64() (v uint64, ok bool) {
+	if n.float != nil || !n.integer.IsUint64() {
+		return 0, false
+	}
+	return n.integer.Uint64(), true
+}
+
+// Rat は値を正確に返す
+func (n *Number) Rat() *big.Rat {
+	if n.float != nil {
+		return new(big.Rat).Set(n.float)
+	}
+	return new(big.Rat).SetInt(n.integer)
+}
+
+// Float64 は値に最も近い float64 を返す
+func (n *Number) Float64() float64 {
+	f, _ := n.Rat().Float64()
+	return f
+}
+
+// 解釈する指数の絶対値の上限
+const maxExponent = 100000
+
+// Number は Integer, Float トークンの値と型をデータモデル m で求める
+// m が nil なら LP64 とする
+// どの型にも収まらない整数は ErrOutOfRange のエラーになる
+func (t *Token) Number(m *DataModel) (*Number, error) {
+	if t.TokenType != Integer && t.TokenType != Float {
+		return nil, fmt.Errorf("clanglex: %v is not a number", t.TokenType)
+	}
+	if m == nil {
+		m = LP64
+	}
+	s := strings.ReplaceAll(t.Literal, "'", "")
+	n := &Number{Radix: 10}
+	if len(s) > 1 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X':
+			n.Radix = 16
+			s = s[2:]
+		case 'b', 'B':
+			n.Radix = 2
+			s = s[2:]
+		default:
+			if t.TokenType == Integer && isDec(s[1]) {
+				n.Radix = 8
+			}
+		}
+	}
+
+	if t.TokenType == Float {
+		return n, t.floatNumber(n, s)
+	}
+	return n, t.intNumber(n, s, m)
+}
+
+func (t *Token) floatNumber(n *Number, s string) error {
+	// 仮数部と指数部の後ろが接尾辞
+	digit, exps := isDec, "eE"
+	if n.Radix == 16 {
+		digit, exps = isHex, "pP"
+	}
+	end := skipDigits(s, 0, digit)
+	if end < len(s) && s[end] == '.' {
+		end = skipDigits(s, end+1, digit)
+	}
+	exp := -1
+	if end < len(s) && strings.IndexByte(exps, s[end]) >= 0 {
+		exp = end + 1
+		end++
+		if end < len(s) && (s[end] == '+' || s[end] == '-') {
+			end++
+		}
+		end = skipDigits(s, end, isDec)
+	}
+	n.Digits, n.Suffix = s[:end], s[end:]
+	n.Type = floatTypes[n.Suffix]
+
+	if exp >= 0 {
+		if e, err := strconv.Atoi(n.Digits[exp:]); err != nil || e > maxExponent || e < -maxExponent {
+			return &LexError{Kind: ErrOutOfRange, Pos: t.Pos, Msg: fmt.Sprintf("exponent of %s is too large", t.Literal)}
+		}
+	}
+	lit := n.Digits
+	if n.Radix == 16 {
+		lit = "0x" + lit
+	}
+	r, ok := new(big.Rat).SetString(lit)
+	if !ok {
+		return &LexError{Kind: ErrMalformedNumber, Pos: t.Pos, Msg: fmt.Sprintf("malformed floating constant %s", t.Literal)}
+	}
+	n.float = r
+	return nil
+}
+
+var floatTypes = map[string]CType{
+	"": TypeDouble, "f": TypeFloat, "F": TypeFloat, "l": TypeLongDouble, "L": TypeLongDouble,
+	"f16": TypeFloat16, "F16": TypeFloat16, "f32": TypeFloat32, "F32": TypeFloat32,
+	"f64": TypeFloat64, "F64": TypeFloat64, "f128": TypeFloat128, "F128": TypeFloat128,
+	"f32x": TypeFloat32x, "F32x": TypeFloat32x, "f64x": TypeFloat64x, "F64x": TypeFloat64x,
+	"f128x": TypeFloat128x, "F128x": TypeFloat128x,
+	"df": TypeDecimal32, "DF": TypeDecimal32, "dd": TypeDecimal64, "DD": TypeDecimal64,
+	"dl": TypeDecimal128, "DL": TypeDecimal128,
+}
+
+func (t *Token) intNumber(n *Number, s string, m *DataModel) error {
+	digit := isDec
+	if n.Radix == 16 {
+		digit = isHex
+	}
+	end := skipDigits(s, 0, digit)
+	n.Digits, n.Suffix = s[:end], s[end:]
+	v, ok := new(big.Int).SetString(n.Digits, n.Radix)
+	if !ok {
+		return &LexError{Kind: ErrMalformedNumber, Pos: t.Pos, Msg: fmt.Sprintf("malformed integer constant %s", t.Literal)}
+	}
+	n.integer = v
+
+	suffix := strings.ToLower(n.Suffix)
+	unsigned := strings.Contains(suffix, "u")
+	size := strings.Trim(suffix, "u")
+
+	// _BitInt(N) (C23)
+	if size == "wb" {
+		n.Type, n.Width = TypeBitInt, v.BitLen()+1
+		if n.Width < 2 {
+			// 符号付きの _BitInt は2ビット以上
+			n.Width = 2
+		}
+		if unsigned {
+			n.Type, n.Width = TypeUnsignedBitInt, v.BitLen()
+			if n.Width == 0 {
+				n.Width = 1
+			}
+		}
+		return nil
+	}
+
+	// MSVC の i8, i16, i32, i64
+	if strings.HasPrefix(size, "i") {
+		c, ok := msIntTypes[size]
+		if !ok {
+			return &LexError{Kind: ErrMalformedNumber, Pos: t.Pos, Msg: fmt.Sprintf("invalid suffix %q on integer constant", n.Suffix)}
+		}
+		if unsigned {
+			c++
+		}
+		return t.fitInt(n, m, []CType{c})
+	}
+
+	// C11 6.4.4.1 の表の順に収まる型を探す
+	var candidates []CType
+	decimal := n.Radix == 10
+	switch {
+	case size == "" && !unsigned && decimal:
+		candidates = []CType{TypeInt, TypeLong, TypeLongLong}
+	case size == "" && !unsigned:
+		candidates = []CType{TypeInt, TypeUnsignedInt, TypeLong, TypeUnsignedLong, TypeLongLong, TypeUnsignedLongLong}
+	case size == "":
+		candidates = []CType{TypeUnsignedInt, TypeUnsignedLong, TypeUnsignedLongLong}
+	case size == "l" && !unsigned && decimal:
+		candidates = []CType{TypeLong, TypeLongLong}
+	case size == "l" && !unsigned:
+		candidates = []CType{TypeLong, TypeUnsignedLong, TypeLongLong, TypeUnsignedLongLong}
+	case size == "l":
+		candidates = []CType{TypeUnsignedLong, TypeUnsignedLongLong}
+	case size == "ll" && !unsigned && decimal:
+		candidates = []CType{TypeLongLong}
+	case size == "ll" && !unsigned:
+		candidates = []CType{TypeLongLong, TypeUnsignedLongLong}
+	case size == "ll":
+		candidates = []CType{TypeUnsignedLongLong}
+	default:
+		return &LexError{Kind: ErrMalformedNumber, Pos: t.Pos, Msg: fmt.Sprintf("invalid suffix %q on integer constant", n.Suffix)}
+	}
+	return t.fitInt(n, m, candidates)
+}
+
+// 符号付きの型(符号なしは +1)
+var msIntTypes = map[string]CType{
+	"i8":  TypeChar,
+	"i16": TypeShort,
+	"i32": TypeInt,
+	"i64": TypeLongLong,
+}
+
+// fitInt は candidates のうち値が収まる最初の型を n.Type にする
+func (t *Token) fitInt(n *Number, m *DataModel, candidates []CType) error {
+	for _, c := range candidates {
+		bits := m.Bits(c)
+		if !c.IsUnsigned() {
+			bits--
+		}
+		if n.integer.BitLen() <= bits {
+			n.Type, n.Width = c, m.Bits(c)
+			return nil
+		}
+	}
+	return &LexError{Kind: ErrOutOfRange, Pos: t.Pos, Msg: fmt.Sprintf("integer constant %s is too large for its type", t.Literal)}
+}
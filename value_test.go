@@ -0,0 +1,186 @@
+package clanglex
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestIntegerNumber(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		model   *DataModel
+		value   string
+		radix   int
+		suffix  string
+		ctype   CType
+		width   int
+	}{
+		{"test decimal", "123", LP64, "123", 10, "", TypeInt, 32},
+		{"test hex", "0xFF", LP64, "255", 16, "", TypeInt, 32},
+		{"test octal", "0777", LP64, "511", 8, "", TypeInt, 32},
+		{"test binary", "0B1010'1010", LP64, "170", 2, "", TypeInt, 32},
+		{"test separator", "1'000'000", LP64, "1000000", 10, "", TypeInt, 32},
+		{"test decimal to long", "2147483648", LP64, "2147483648", 10, "", TypeLong, 64},
+		{"test decimal to long long", "2147483648", ILP32, "2147483648", 10, "", TypeLongLong, 64},
+		{"test hex to unsigned int", "0xFFFFFFFF", ILP32, "4294967295", 16, "", TypeUnsignedInt, 32},
+		{"test hex to unsigned long long", "0xFFFFFFFFFFFFFFFF", LLP64, "18446744073709551615", 16, "", TypeUnsignedLongLong, 64},
+		{"test 16 bit int", "32767", IP16, "32767", 10, "", TypeInt, 16},
+		{"test 16 bit long", "32768", IP16, "32768", 10, "", TypeLong, 32},
+		{"test 16 bit unsigned", "0xFFFF", IP16, "65535", 16, "", TypeUnsignedInt, 16},
+		{"test 16 bit unsigned long", "65536u", IP16, "65536", 10, "u", TypeUnsignedLong, 32},
+		{"test u", "1U", LP64, "1", 10, "U", TypeUnsignedInt, 32},
+		{"test l", "1l", ILP32, "1", 10, "l", TypeLong, 32},
+		{"test ul", "0x1UL", LP64, "1", 16, "UL", TypeUnsignedLong, 64},
+		{"test ll", "1LL", LP64, "1", 10, "LL", TypeLongLong, 64},
+		{"test llu", "1llu", LP64, "1", 10, "llu", TypeUnsignedLongLong, 64},
+		{"test wb", "255wb", LP64, "255", 10, "wb", TypeBitInt, 9},
+		{"test uwb", "255uwb", LP64, "255", 10, "uwb", TypeUnsignedBitInt, 8},
+		{"test zero uwb", "0uwb", LP64, "0", 10, "uwb", TypeUnsignedBitInt, 1},
+		{"test zero wb", "0wb", LP64, "0", 10, "wb", TypeBitInt, 2},
+		{"test one wb", "1wb", LP64, "1", 10, "wb", TypeBitInt, 2},
+		{"test nil model", "2147483648", nil, "2147483648", 10, "", TypeLong, 64},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		ts, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := ts[0].Number(tt.model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.IsFloat() || n.Int().String() != tt.value {
+			t.Errorf("got value=%v, expect=%v", n.Int(), tt.value)
+		}
+		if n.Radix != tt.radix || n.Suffix != tt.suffix {
+			t.Errorf("got radix=%v suffix=%q, expect radix=%v suffix=%q", n.Radix, n.Suffix, tt.radix, tt.suffix)
+		}
+		if n.Type != tt.ctype || n.Width != tt.width {
+			t.Errorf("got type=%v width=%v, expect type=%v width=%v", n.Type, n.Width, tt.ctype, tt.width)
+		}
+	}
+}
+
+func TestMSVCNumber(t *testing.T) {
+	ts, err := Lexicalize("100i64 0xFFui8", WithDialect(MSVC(C99)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := ts[0].Number(LLP64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Type != TypeLongLong {
+		t.Errorf("got type=%v", n.Type)
+	}
+	n, err = ts[1].Number(LLP64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := n.Uint64(); n.Type != TypeUnsignedChar || v != 255 {
+		t.Errorf("got type=%v value=%v", n.Type, v)
+	}
+	if _, err := ts[0].Number(IP16); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUint64(t *testing.T) {
+	ts, err := Lexicalize("0xFFFFFFFFFFFFFFFF 0x1FFFFFFFFFFFFFFFFwb 1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ := ts[0].Number(nil)
+	if v, ok := n.Uint64(); !ok || v != 0xFFFFFFFFFFFFFFFF {
+		t.Errorf("got=%v %v", v, ok)
+	}
+	n, _ = ts[1].Number(nil)
+	if _, ok := n.Uint64(); ok || n.Width != 66 {
+		t.Errorf("expect overflow, width=%v", n.Width)
+	}
+	n, _ = ts[2].Number(nil)
+	if _, ok := n.Uint64(); ok {
+		t.Errorf("float must not be uint64")
+	}
+}
+
+func TestFloatNumber(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		value   string
+		f       float64
+		radix   int
+		digits  string
+		suffix  string
+		ctype   CType
+	}{
+		{"test decimal", "1.5", "3/2", 1.5, 10, "1.5", "", TypeDouble},
+		{"test exponent", "1.5e-3f", "3/2000", 1.5e-3, 10, "1.5e-3", "f", TypeFloat},
+		{"test leading period", ".25L", "1/4", 0.25, 10, ".25", "L", TypeLongDouble},
+		{"test octal prefix", "012e3", "12000", 12000, 10, "012e3", "", TypeDouble},
+		{"test hex", "0x1.8p3", "12", 12, 16, "1.8p3", "", TypeDouble},
+		{"test hex suffix", "0x1.fp-1f", "31/32", 0.96875, 16, "1.fp-1", "f", TypeFloat},
+		{"test f16", "1.0f16", "1", 1, 10, "1.0", "f16", TypeFloat16},
+		{"test decimal float", "0.1dd", "1/10", 0.1, 10, "0.1", "dd", TypeDecimal64},
+		{"test separator", "1'000.5", "2001/2", 1000.5, 10, "1000.5", "", TypeDouble},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		ts, err := Lexicalize(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := ts[0].Number(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !n.IsFloat() || n.Rat().RatString() != tt.value || n.Float64() != tt.f {
+			t.Errorf("got value=%v %v, expect=%v %v", n.Rat().RatString(), n.Float64(), tt.value, tt.f)
+		}
+		if n.Radix != tt.radix || n.Digits != tt.digits || n.Suffix != tt.suffix || n.Type != tt.ctype {
+			t.Errorf("got radix=%v digits=%q suffix=%q type=%v", n.Radix, n.Digits, n.Suffix, n.Type)
+		}
+	}
+
+	// 0.1 は正確に 1/10
+	ts, _ := Lexicalize("0.1")
+	n, _ := ts[0].Number(nil)
+	if n.Rat().Cmp(big.NewRat(1, 10)) != 0 {
+		t.Errorf("got=%v", n.Rat())
+	}
+}
+
+func TestNumberError(t *testing.T) {
+	testTbl := []struct {
+		comment string
+		src     string
+		model   *DataModel
+		kind    ErrorKind
+	}{
+		{"test too large", "18446744073709551616", LP64, ErrOutOfRange},
+		{"test decimal too large for long long", "9223372036854775808", LP64, ErrOutOfRange},
+		{"test i64 on 16 bit int", "100000i32", IP16, ErrOutOfRange},
+		{"test exponent too large", "1e999999999", nil, ErrOutOfRange},
+	}
+
+	for _, tt := range testTbl {
+		t.Logf("%s", tt.comment)
+		ts, err := Lexicalize(tt.src, WithDialect(MSVC(C99)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ts[0].Number(tt.model); !errors.Is(err, tt.kind) {
+			t.Errorf("got=%v, expect=%v", err, tt.kind)
+		}
+	}
+
+	if _, err := (&Token{TokenType: Word, Literal: "a"}).Number(nil); err == nil {
+		t.Errorf("expect error")
+	}
+}